@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/kyomel/blog-management/configs"
+	"github.com/kyomel/blog-management/internal/database"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+func main() {
+	out := flag.String("out", "", "write the backup to this file instead of stdout")
+	restore := flag.String("restore", "", "restore this backup file into a database without content")
+	includePasswordHashes := flag.Bool("include-password-hashes", false, "include user password hashes in the backup")
+	flag.Parse()
+
+	if *out != "" && *restore != "" {
+		fmt.Fprintln(os.Stderr, "-out and -restore cannot be combined")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	config, err := configs.LoadConfig()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	if err := database.Connect(&config.Database); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	if err := database.Migrate(); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	db, err := database.GetDB().DB()
+	if err != nil {
+		log.Fatal("Failed to get database instance:", err)
+	}
+
+	ctx := context.Background()
+	backupService := services.NewBackupService(repositories.NewBackupRepository(db))
+
+	if *restore != "" {
+		f, err := os.Open(*restore)
+		if err != nil {
+			log.Fatal("Failed to open backup file:", err)
+		}
+		defer f.Close()
+
+		var archive models.BackupArchive
+		if err := json.NewDecoder(f).Decode(&archive); err != nil {
+			log.Fatal("Failed to parse backup file:", err)
+		}
+
+		result, err := backupService.Restore(ctx, &archive)
+		if err != nil {
+			log.Fatal("Restore failed:", err)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	archive, err := backupService.Export(ctx, *includePasswordHashes)
+	if err != nil {
+		log.Fatal("Backup failed:", err)
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		w, err = os.Create(*out)
+		if err != nil {
+			log.Fatal("Failed to create backup file:", err)
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(archive); err != nil {
+		log.Fatal("Failed to write backup:", err)
+	}
+	if err := w.Close(); err != nil {
+		log.Fatal("Failed to write backup:", err)
+	}
+}
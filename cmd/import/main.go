@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/kyomel/blog-management/configs"
+	"github.com/kyomel/blog-management/internal/database"
+	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/services/importer"
+	"github.com/kyomel/blog-management/internal/setup"
+)
+
+func main() {
+	format := flag.String("format", "", "export format: wordpress or ghost (detected from the file extension if empty)")
+	file := flag.String("file", "", "path to the export file")
+	author := flag.String("author", "", "email of the user who owns posts without a matching author")
+	baseURL := flag.String("base-url", "", "URL of the old site, used to resolve relative image URLs")
+	skipMedia := flag.Bool("skip-media", false, "keep image URLs instead of rehosting them")
+	flag.Parse()
+
+	if *file == "" || *author == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *format == "" {
+		detected, err := importer.DetectFormat(*file)
+		if err != nil {
+			log.Fatal("Could not detect export format, use -format:", err)
+		}
+		*format = detected
+	}
+
+	config, err := configs.LoadConfig()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	if err := database.Connect(&config.Database); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	if err := database.Migrate(); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	db, err := database.GetDB().DB()
+	if err != nil {
+		log.Fatal("Failed to get database instance:", err)
+	}
+
+	ctx := context.Background()
+
+	defaultAuthor, err := repositories.NewUserRepository(db).FindByEmail(ctx, *author)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		log.Fatalf("No user with email %s", *author)
+	}
+	if err != nil {
+		log.Fatal("Failed to look up author:", err)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal("Failed to open export file:", err)
+	}
+	defer f.Close()
+
+	doc, err := importer.Parse(*format, f)
+	if err != nil {
+		log.Fatal("Failed to parse export file:", err)
+	}
+
+	contentImporter, err := setup.NewImporter(db, config.Cloudinary)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := contentImporter.Import(ctx, doc, importer.Options{
+		DefaultAuthorID: defaultAuthor.ID,
+		BaseURL:         *baseURL,
+		SkipMedia:       *skipMedia,
+	})
+	if err != nil {
+		log.Fatal("Import failed:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/configs"
+	"github.com/kyomel/blog-management/internal/database"
+	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/services/frontmatter"
+	"github.com/kyomel/blog-management/internal/setup"
+)
+
+func main() {
+	exportPath := flag.String("export", "", "write all posts to this directory or .zip file")
+	importPath := flag.String("import", "", "create or update posts from this directory or .zip file")
+	author := flag.String("author", "", "email of the user who owns new posts without a known author")
+	flag.Parse()
+
+	if (*exportPath == "") == (*importPath == "") {
+		fmt.Fprintln(os.Stderr, "exactly one of -export or -import is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	config, err := configs.LoadConfig()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	if err := database.Connect(&config.Database); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	if err := database.Migrate(); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	db, err := database.GetDB().DB()
+	if err != nil {
+		log.Fatal("Failed to get database instance:", err)
+	}
+
+	ctx := context.Background()
+	markdownService := setup.NewMarkdownService(db)
+
+	if *exportPath != "" {
+		count, err := exportPosts(ctx, markdownService, *exportPath)
+		if err != nil {
+			log.Fatal("Export failed:", err)
+		}
+		log.Printf("Exported %d posts to %s", count, *exportPath)
+		return
+	}
+
+	var defaultAuthorID uuid.UUID
+	if *author != "" {
+		user, err := repositories.NewUserRepository(db).FindByEmail(ctx, *author)
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			log.Fatalf("No user with email %s", *author)
+		}
+		if err != nil {
+			log.Fatal("Failed to look up author:", err)
+		}
+		defaultAuthorID = user.ID
+	}
+
+	report, err := importPosts(ctx, markdownService, *importPath, defaultAuthorID)
+	if err != nil {
+		log.Fatal("Import failed:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func exportPosts(ctx context.Context, markdownService *frontmatter.Service, target string) (int, error) {
+	if !isZip(target) {
+		return markdownService.ExportDir(ctx, target)
+	}
+
+	f, err := os.Create(target)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := markdownService.ExportZip(ctx, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return count, err
+}
+
+func importPosts(ctx context.Context, markdownService *frontmatter.Service, source string, defaultAuthorID uuid.UUID) (*frontmatter.ImportReport, error) {
+	if !isZip(source) {
+		return markdownService.ImportDir(ctx, source, defaultAuthorID)
+	}
+
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return markdownService.ImportZip(ctx, f, info.Size(), defaultAuthorID)
+}
+
+func isZip(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".zip")
+}
@@ -1,11 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/kyomel/blog-management/configs"
 	"github.com/kyomel/blog-management/internal/database"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/services"
 	"github.com/kyomel/blog-management/internal/setup"
 
 	"github.com/gin-gonic/gin"
@@ -32,8 +41,26 @@ func main() {
 
 	gin.SetMode(config.Server.Mode)
 
+	if gin.Mode() == gin.ReleaseMode {
+		if err := config.CheckReleaseSecrets(); err != nil {
+			log.Fatalf("Refusing to start in release mode: %v", err)
+		}
+	}
+
 	router := gin.Default()
 
+	// Login throttling and view dedupe key on the client IP, so it must not
+	// be taken from headers anyone can send
+	var trustedProxies []string
+	for _, proxy := range strings.Split(config.Server.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status":  "ok",
@@ -53,16 +80,167 @@ func main() {
 		refreshExpiry = 7 * 24 * time.Hour
 	}
 
-	setup.SetupAuth(router, db, setup.AuthConfig{
-		AccessSecret:  config.JWT.AccessSecret,
-		RefreshSecret: config.JWT.RefreshSecret,
-		AccessExpiry:  accessExpiry,
-		RefreshExpiry: refreshExpiry,
-		Cloudinary:    config.Cloudinary,
+	trashPurgeInterval, err := time.ParseDuration(config.Trash.PurgeInterval)
+	if err != nil {
+		log.Printf("Warning: Invalid trash purge interval format, using default 1h: %v", err)
+		trashPurgeInterval = time.Hour
+	}
+
+	// The name is passed on to PostgreSQL, so it must be an IANA zone
+	timezone, err := time.LoadLocation(config.Blog.Timezone)
+	if err != nil || timezone.String() == "Local" {
+		log.Fatalf("Invalid BLOG_TIMEZONE %q: must be an IANA timezone name", config.Blog.Timezone)
+	}
+
+	viewDedupeWindow, err := time.ParseDuration(config.Views.DedupeWindow)
+	if err != nil {
+		log.Printf("Warning: Invalid view dedupe window format, using default 30m: %v", err)
+		viewDedupeWindow = 30 * time.Minute
+	}
+
+	viewFlushInterval, err := time.ParseDuration(config.Views.FlushInterval)
+	if err != nil || viewFlushInterval <= 0 {
+		log.Printf("Warning: Invalid view flush interval format, using default 10s: %v", err)
+		viewFlushInterval = 10 * time.Second
+	}
+
+	passwordResetTTL, err := time.ParseDuration(config.Account.PasswordResetTTL)
+	if err != nil || passwordResetTTL <= 0 {
+		log.Printf("Warning: Invalid password reset TTL format, using default 1h: %v", err)
+		passwordResetTTL = time.Hour
+	}
+
+	emailTokenExpiry, err := time.ParseDuration(config.Account.VerificationExpiry)
+	if err != nil || emailTokenExpiry <= 0 {
+		log.Printf("Warning: Invalid email verification expiry format, using default 48h: %v", err)
+		emailTokenExpiry = 48 * time.Hour
+	}
+
+	verificationResend, err := time.ParseDuration(config.Account.VerificationResend)
+	if err != nil {
+		log.Printf("Warning: Invalid email verification resend interval format, using default 5m: %v", err)
+		verificationResend = 5 * time.Minute
+	}
+
+	unverifiedPolicy := services.UnverifiedPolicy(config.Account.UnverifiedPolicy)
+	switch unverifiedPolicy {
+	case services.UnverifiedAllow, services.UnverifiedReadOnly, services.UnverifiedBlock:
+	default:
+		log.Fatalf("Invalid UNVERIFIED_USER_POLICY %q: must be allow, read_only or block", config.Account.UnverifiedPolicy)
+	}
+
+	challengeTTL, err := time.ParseDuration(config.TwoFactor.ChallengeTTL)
+	if err != nil || challengeTTL <= 0 {
+		log.Printf("Warning: Invalid two-factor challenge TTL format, using default 5m: %v", err)
+		challengeTTL = 5 * time.Minute
+	}
+
+	var twoFactorRoles []models.UserRole
+	for _, role := range strings.Split(config.TwoFactor.RequiredRoles, ",") {
+		switch role = strings.TrimSpace(role); models.UserRole(role) {
+		case "":
+		case models.RoleAdmin, models.RoleUser:
+			twoFactorRoles = append(twoFactorRoles, models.UserRole(role))
+		default:
+			log.Fatalf("Invalid role %q in TWO_FACTOR_REQUIRED_ROLES", role)
+		}
+	}
+
+	loginBackoffBase, err := time.ParseDuration(config.Login.BackoffBase)
+	if err != nil || loginBackoffBase <= 0 {
+		log.Printf("Warning: Invalid login backoff base format, using default 1s: %v", err)
+		loginBackoffBase = time.Second
+	}
+
+	loginBackoffMax, err := time.ParseDuration(config.Login.BackoffMax)
+	if err != nil || loginBackoffMax < loginBackoffBase {
+		log.Printf("Warning: Invalid login backoff max, using default 15m: %v", err)
+		loginBackoffMax = 15 * time.Minute
+	}
+
+	lockoutDuration, err := time.ParseDuration(config.Login.LockoutDuration)
+	if err != nil || lockoutDuration <= 0 {
+		log.Printf("Warning: Invalid login lockout duration format, using default 30m: %v", err)
+		lockoutDuration = 30 * time.Minute
+	}
+
+	if config.Login.LockoutThreshold < 1 {
+		log.Fatalf("Invalid LOGIN_LOCKOUT_THRESHOLD %d: must be at least 1", config.Login.LockoutThreshold)
+	}
+
+	if config.Dashboard.StaleDraftDays < 1 || config.Dashboard.SignupDays < 1 {
+		log.Fatalf("Invalid DASHBOARD_STALE_DRAFT_DAYS %d or DASHBOARD_SIGNUP_DAYS %d: must be at least 1",
+			config.Dashboard.StaleDraftDays, config.Dashboard.SignupDays)
+	}
+
+	shutdownJobs := setup.SetupAuth(router, db, setup.AuthConfig{
+		JWT:                config.JWT,
+		AccessExpiry:       accessExpiry,
+		RefreshExpiry:      refreshExpiry,
+		Cloudinary:         config.Cloudinary,
+		TrashRetention:     time.Duration(config.Trash.RetentionDays) * 24 * time.Hour,
+		TrashPurgeInterval: trashPurgeInterval,
+		Timezone:           timezone,
+		ViewDedupeWindow:   viewDedupeWindow,
+		ViewFlushInterval:  viewFlushInterval,
+		CountryHeader:      config.Views.CountryHeader,
+		BlogTitle:          config.Blog.Title,
+		BlogURL:            config.Blog.URL,
+		Mail:               config.Mail,
+		PasswordReset: services.PasswordResetConfig{
+			URL: config.Account.PasswordResetURL,
+			TTL: passwordResetTTL,
+		},
+		EmailVerification: services.EmailVerificationConfig{
+			URL:            config.Account.VerificationURL,
+			Policy:         unverifiedPolicy,
+			ResendInterval: verificationResend,
+		},
+		EmailTokenSecret: config.Account.VerificationSecret,
+		EmailTokenExpiry: emailTokenExpiry,
+		TwoFactor: services.TwoFactorConfig{
+			Issuer:        config.TwoFactor.Issuer,
+			ChallengeTTL:  challengeTTL,
+			RequiredRoles: twoFactorRoles,
+		},
+		LoginThrottle: services.LoginThrottleConfig{
+			FreeAttempts:     config.Login.FreeAttempts,
+			IPFreeAttempts:   config.Login.IPFreeAttempts,
+			BaseDelay:        loginBackoffBase,
+			MaxDelay:         loginBackoffMax,
+			LockoutThreshold: config.Login.LockoutThreshold,
+			LockoutDuration:  lockoutDuration,
+		},
+		Dashboard: services.DashboardConfig{
+			StaleDraftDays: config.Dashboard.StaleDraftDays,
+			SignupDays:     config.Dashboard.SignupDays,
+		},
+		OIDC: config.OIDC,
 	})
 
-	log.Printf("Server starting on port %s", config.Server.Port)
-	if err := router.Run(":" + config.Server.Port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	server := &http.Server{
+		Addr:    ":" + config.Server.Port,
+		Handler: router,
+	}
+
+	go func() {
+		log.Printf("Server starting on port %s", config.Server.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	log.Println("Shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown failed: %v", err)
 	}
+	shutdownJobs(shutdownCtx)
+	log.Println("Server stopped")
 }
@@ -10,10 +10,19 @@ import (
 )
 
 type Config struct {
-	Server     ServerConfig     `mapstructure:"server"`
-	Database   DatabaseConfig   `mapstructure:"database"`
-	JWT        JWTConfig        `mapstructure:"jwt"`
-	Cloudinary CloudinaryConfig `mapstructure:"cloudinary"`
+	Server     ServerConfig         `mapstructure:"server"`
+	Database   DatabaseConfig       `mapstructure:"database"`
+	JWT        JWTConfig            `mapstructure:"jwt"`
+	Cloudinary CloudinaryConfig     `mapstructure:"cloudinary"`
+	Trash      TrashConfig          `mapstructure:"trash"`
+	Blog       BlogConfig           `mapstructure:"blog"`
+	Views      ViewsConfig          `mapstructure:"views"`
+	Mail       MailConfig           `mapstructure:"mail"`
+	Account    AccountConfig        `mapstructure:"account"`
+	TwoFactor  TwoFactorConfig      `mapstructure:"two_factor"`
+	Login      LoginConfig          `mapstructure:"login"`
+	Dashboard  DashboardConfig      `mapstructure:"dashboard"`
+	OIDC       []OIDCProviderConfig `mapstructure:"oidc"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -49,8 +58,9 @@ func LoadConfig() (*Config, error) {
 	// Map environment variables to configuration
 	cfg := Config{
 		Server: ServerConfig{
-			Port: viper.GetString("SERVER_PORT"),
-			Mode: viper.GetString("SERVER_MODE"),
+			Port:           viper.GetString("SERVER_PORT"),
+			Mode:           viper.GetString("SERVER_MODE"),
+			TrustedProxies: viper.GetString("TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Host:     viper.GetString("DB_HOST"),
@@ -61,19 +71,92 @@ func LoadConfig() (*Config, error) {
 			SSLMode:  viper.GetString("DB_SSLMODE"),
 		},
 		JWT: JWTConfig{
-			AccessSecret:  viper.GetString("JWT_ACCESS_SECRET"),
-			RefreshSecret: viper.GetString("JWT_REFRESH_SECRET"),
-			AccessExpiry:  viper.GetString("JWT_ACCESS_EXPIRY"),
-			RefreshExpiry: viper.GetString("JWT_REFRESH_EXPIRY"),
+			AccessSecret:         viper.GetString("JWT_ACCESS_SECRET"),
+			RefreshSecret:        viper.GetString("JWT_REFRESH_SECRET"),
+			AccessExpiry:         viper.GetString("JWT_ACCESS_EXPIRY"),
+			RefreshExpiry:        viper.GetString("JWT_REFRESH_EXPIRY"),
+			Algorithm:            viper.GetString("JWT_ALGORITHM"),
+			SigningKeyFile:       viper.GetString("JWT_SIGNING_KEY_FILE"),
+			VerificationKeyFiles: viper.GetString("JWT_VERIFICATION_KEY_FILES"),
+			PreviousSecrets:      viper.GetString("JWT_PREVIOUS_SECRETS"),
 		},
 		Cloudinary: CloudinaryConfig{
-			CloudName: viper.GetString("CLOUDINARY_CLOUD_NAME"),
-			APIKey:    viper.GetString("CLOUDINARY_API_KEY"),
-			APISecret: viper.GetString("CLOUDINARY_API_SECRET"),
-			Folder:    viper.GetString("CLOUDINARY_FOLDER"),
+			CloudName:   viper.GetString("CLOUDINARY_CLOUD_NAME"),
+			APIKey:      viper.GetString("CLOUDINARY_API_KEY"),
+			APISecret:   viper.GetString("CLOUDINARY_API_SECRET"),
+			Folder:      viper.GetString("CLOUDINARY_FOLDER"),
+			MediaFolder: viper.GetString("CLOUDINARY_MEDIA_FOLDER"),
+		},
+		Trash: TrashConfig{
+			RetentionDays: viper.GetInt("TRASH_RETENTION_DAYS"),
+			PurgeInterval: viper.GetString("TRASH_PURGE_INTERVAL"),
+		},
+		Blog: BlogConfig{
+			Timezone: viper.GetString("BLOG_TIMEZONE"),
+			Title:    viper.GetString("BLOG_TITLE"),
+			URL:      viper.GetString("BLOG_URL"),
+		},
+		Views: ViewsConfig{
+			DedupeWindow:  viper.GetString("VIEW_DEDUPE_WINDOW"),
+			FlushInterval: viper.GetString("VIEW_FLUSH_INTERVAL"),
+			CountryHeader: viper.GetString("VIEW_COUNTRY_HEADER"),
+		},
+		Mail: MailConfig{
+			Driver:       viper.GetString("MAIL_DRIVER"),
+			From:         viper.GetString("MAIL_FROM"),
+			LogDir:       viper.GetString("MAIL_LOG_DIR"),
+			SMTPHost:     viper.GetString("SMTP_HOST"),
+			SMTPPort:     viper.GetString("SMTP_PORT"),
+			SMTPUsername: viper.GetString("SMTP_USERNAME"),
+			SMTPPassword: viper.GetString("SMTP_PASSWORD"),
+		},
+		Account: AccountConfig{
+			PasswordResetURL:   viper.GetString("PASSWORD_RESET_URL"),
+			PasswordResetTTL:   viper.GetString("PASSWORD_RESET_TTL"),
+			VerificationURL:    viper.GetString("EMAIL_VERIFICATION_URL"),
+			VerificationSecret: viper.GetString("EMAIL_VERIFICATION_SECRET"),
+			VerificationExpiry: viper.GetString("EMAIL_VERIFICATION_EXPIRY"),
+			VerificationResend: viper.GetString("EMAIL_VERIFICATION_RESEND_INTERVAL"),
+			UnverifiedPolicy:   viper.GetString("UNVERIFIED_USER_POLICY"),
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:        viper.GetString("TWO_FACTOR_ISSUER"),
+			ChallengeTTL:  viper.GetString("TWO_FACTOR_CHALLENGE_TTL"),
+			RequiredRoles: viper.GetString("TWO_FACTOR_REQUIRED_ROLES"),
+		},
+		Login: LoginConfig{
+			FreeAttempts:     viper.GetInt("LOGIN_FREE_ATTEMPTS"),
+			IPFreeAttempts:   viper.GetInt("LOGIN_IP_FREE_ATTEMPTS"),
+			BackoffBase:      viper.GetString("LOGIN_BACKOFF_BASE"),
+			BackoffMax:       viper.GetString("LOGIN_BACKOFF_MAX"),
+			LockoutThreshold: viper.GetInt("LOGIN_LOCKOUT_THRESHOLD"),
+			LockoutDuration:  viper.GetString("LOGIN_LOCKOUT_DURATION"),
+		},
+		Dashboard: DashboardConfig{
+			StaleDraftDays: viper.GetInt("DASHBOARD_STALE_DRAFT_DAYS"),
+			SignupDays:     viper.GetInt("DASHBOARD_SIGNUP_DAYS"),
 		},
 	}
 
+	for _, name := range strings.Split(viper.GetString("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		viper.SetDefault(prefix+"SCOPES", "openid email profile")
+		viper.SetDefault(prefix+"DEFAULT_ROLE", "user")
+		cfg.OIDC = append(cfg.OIDC, OIDCProviderConfig{
+			Name:         name,
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			RedirectURL:  viper.GetString(prefix + "REDIRECT_URL"),
+			Scopes:       viper.GetString(prefix + "SCOPES"),
+			DefaultRole:  viper.GetString(prefix + "DEFAULT_ROLE"),
+		})
+	}
+
 	// Debug: Print configuration values (without sensitive data)
 	log.Printf("Configuration values:")
 	log.Printf("Server: port=%s, mode=%s", cfg.Server.Port, cfg.Server.Mode)
@@ -116,6 +199,69 @@ func checkRequiredConfig() error {
 	return nil
 }
 
+// Insecure defaults that let the server run in development. Release mode
+// refuses to start while any of them is in use.
+const (
+	defaultAccessSecret            = "default_access_secret_change_me"
+	defaultRefreshSecret           = "default_refresh_secret_change_me"
+	defaultEmailVerificationSecret = "default_email_verification_secret_change_me"
+)
+
+// minSecretLength is the shortest HMAC secret accepted in release mode
+const minSecretLength = 32
+
+// CheckReleaseSecrets returns an error if a secret is still at its default
+// or too short to be safe in production
+func (c *Config) CheckReleaseSecrets() error {
+	type secret struct {
+		name, value, defaultValue string
+	}
+	secrets := []secret{
+		{"EMAIL_VERIFICATION_SECRET", c.Account.VerificationSecret, defaultEmailVerificationSecret},
+	}
+	if c.JWT.Algorithm == "HS256" || c.JWT.Algorithm == "" {
+		if c.JWT.AccessSecret == c.JWT.RefreshSecret {
+			return fmt.Errorf("JWT_ACCESS_SECRET and JWT_REFRESH_SECRET must differ")
+		}
+		secrets = append(secrets,
+			secret{"JWT_ACCESS_SECRET", c.JWT.AccessSecret, defaultAccessSecret},
+			secret{"JWT_REFRESH_SECRET", c.JWT.RefreshSecret, defaultRefreshSecret},
+		)
+	}
+
+	for _, secret := range secrets {
+		if secret.value == secret.defaultValue {
+			return fmt.Errorf("%s is set to its insecure default", secret.name)
+		}
+		if len(secret.value) < minSecretLength {
+			return fmt.Errorf("%s must be at least %d characters", secret.name, minSecretLength)
+		}
+	}
+
+	// Retired secrets still verify tokens, so a default moved here would let
+	// anyone forge them
+	for _, previous := range SplitList(c.JWT.PreviousSecrets) {
+		if previous == defaultAccessSecret || previous == defaultRefreshSecret {
+			return fmt.Errorf("JWT_PREVIOUS_SECRETS contains an insecure default")
+		}
+		if len(previous) < minSecretLength {
+			return fmt.Errorf("every JWT_PREVIOUS_SECRETS entry must be at least %d characters", minSecretLength)
+		}
+	}
+	return nil
+}
+
+// SplitList splits a comma separated setting, dropping empty entries
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func setDefaults() {
 	viper.SetDefault("SERVER_PORT", "8080")
 	viper.SetDefault("SERVER_MODE", "debug")
@@ -124,18 +270,58 @@ func setDefaults() {
 	viper.SetDefault("DB_PORT", "5432")
 	viper.SetDefault("DB_SSLMODE", "disable")
 
-	viper.SetDefault("JWT_ACCESS_SECRET", "default_access_secret_change_me")
-	viper.SetDefault("JWT_REFRESH_SECRET", "default_refresh_secret_change_me")
+	viper.SetDefault("JWT_ACCESS_SECRET", defaultAccessSecret)
+	viper.SetDefault("JWT_REFRESH_SECRET", defaultRefreshSecret)
+	viper.SetDefault("JWT_ALGORITHM", "HS256")
 	viper.SetDefault("JWT_ACCESS_EXPIRY", "15m")
 	viper.SetDefault("JWT_REFRESH_EXPIRY", "7d")
 
 	viper.SetDefault("CLOUDINARY_FOLDER", "avatars")
+	viper.SetDefault("CLOUDINARY_MEDIA_FOLDER", "media")
+
+	viper.SetDefault("TRASH_RETENTION_DAYS", 0)
+	viper.SetDefault("TRASH_PURGE_INTERVAL", "1h")
+
+	viper.SetDefault("BLOG_TIMEZONE", "UTC")
+	viper.SetDefault("BLOG_TITLE", "Blog")
+	viper.SetDefault("BLOG_URL", "http://localhost:3000")
+
+	viper.SetDefault("DASHBOARD_STALE_DRAFT_DAYS", 30)
+	viper.SetDefault("DASHBOARD_SIGNUP_DAYS", 7)
+
+	viper.SetDefault("VIEW_DEDUPE_WINDOW", "30m")
+	viper.SetDefault("VIEW_FLUSH_INTERVAL", "10s")
+
+	viper.SetDefault("MAIL_DRIVER", "log")
+	viper.SetDefault("MAIL_FROM", "Blog <no-reply@localhost>")
+	viper.SetDefault("SMTP_PORT", "587")
+
+	viper.SetDefault("PASSWORD_RESET_URL", "http://localhost:3000/reset-password")
+	viper.SetDefault("PASSWORD_RESET_TTL", "1h")
+	viper.SetDefault("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email")
+	viper.SetDefault("EMAIL_VERIFICATION_SECRET", defaultEmailVerificationSecret)
+	viper.SetDefault("EMAIL_VERIFICATION_EXPIRY", "48h")
+	viper.SetDefault("EMAIL_VERIFICATION_RESEND_INTERVAL", "5m")
+	viper.SetDefault("UNVERIFIED_USER_POLICY", "read_only")
+
+	viper.SetDefault("TWO_FACTOR_ISSUER", "Blog Management")
+	viper.SetDefault("TWO_FACTOR_CHALLENGE_TTL", "5m")
+
+	viper.SetDefault("LOGIN_FREE_ATTEMPTS", 3)
+	viper.SetDefault("LOGIN_IP_FREE_ATTEMPTS", 10)
+	viper.SetDefault("LOGIN_BACKOFF_BASE", "1s")
+	viper.SetDefault("LOGIN_BACKOFF_MAX", "15m")
+	viper.SetDefault("LOGIN_LOCKOUT_THRESHOLD", 10)
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", "30m")
 
 }
 
 type ServerConfig struct {
 	Port string `mapstructure:"port"`
 	Mode string `mapstructure:"mode"`
+	// TrustedProxies is a comma separated list of proxy IPs or CIDRs whose
+	// X-Forwarded-For header is believed; empty trusts no proxy
+	TrustedProxies string `mapstructure:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
@@ -152,6 +338,16 @@ type JWTConfig struct {
 	RefreshSecret string `mapstructure:"refresh_secret"`
 	AccessExpiry  string `mapstructure:"access_expiry"`
 	RefreshExpiry string `mapstructure:"refresh_expiry"`
+	// Algorithm is HS256, which signs with the secrets above, or RS256 or
+	// EdDSA, which sign with the private key in SigningKeyFile
+	Algorithm      string `mapstructure:"algorithm"`
+	SigningKeyFile string `mapstructure:"signing_key_file"`
+	// VerificationKeyFiles is a comma separated list of PEM keys that are
+	// still accepted and published, e.g. the previous key after a rotation
+	VerificationKeyFiles string `mapstructure:"verification_key_files"`
+	// PreviousSecrets is a comma separated list of retired HS256 secrets
+	// that are still accepted
+	PreviousSecrets string `mapstructure:"previous_secrets"`
 }
 
 type CloudinaryConfig struct {
@@ -159,4 +355,91 @@ type CloudinaryConfig struct {
 	APIKey    string `mapstructure:"api_key"`
 	APISecret string `mapstructure:"api_secret"`
 	Folder    string `mapstructure:"folder"`
+	// MediaFolder holds images rehosted by the importer
+	MediaFolder string `mapstructure:"media_folder"`
+}
+
+type TrashConfig struct {
+	RetentionDays int    `mapstructure:"retention_days"`
+	PurgeInterval string `mapstructure:"purge_interval"`
+}
+
+type BlogConfig struct {
+	// Timezone is an IANA name such as Asia/Jakarta
+	Timezone string `mapstructure:"timezone"`
+	// Title names the blog in feeds
+	Title string `mapstructure:"title"`
+	// URL is the public frontend; feeds link to <URL>/posts/<slug> and
+	// <URL>/authors/<username>
+	URL string `mapstructure:"url"`
+}
+
+type DashboardConfig struct {
+	// StaleDraftDays is how long a draft goes unchanged before the dashboard
+	// counts it as stale
+	StaleDraftDays int `mapstructure:"stale_draft_days"`
+	// SignupDays is how far back the dashboard counts signups
+	SignupDays int `mapstructure:"signup_days"`
+}
+
+type ViewsConfig struct {
+	DedupeWindow  string `mapstructure:"dedupe_window"`
+	FlushInterval string `mapstructure:"flush_interval"`
+	// CountryHeader names a header set by a CDN or proxy, e.g. CF-IPCountry
+	CountryHeader string `mapstructure:"country_header"`
+}
+
+type MailConfig struct {
+	// Driver is smtp, or log to write emails to LogDir (or the log) instead
+	Driver       string `mapstructure:"driver"`
+	From         string `mapstructure:"from"`
+	LogDir       string `mapstructure:"log_dir"`
+	SMTPHost     string `mapstructure:"smtp_host"`
+	SMTPPort     string `mapstructure:"smtp_port"`
+	SMTPUsername string `mapstructure:"smtp_username"`
+	SMTPPassword string `mapstructure:"smtp_password"`
+}
+
+type AccountConfig struct {
+	// PasswordResetURL is the frontend page that receives reset tokens
+	PasswordResetURL string `mapstructure:"password_reset_url"`
+	PasswordResetTTL string `mapstructure:"password_reset_ttl"`
+	// VerificationURL is the frontend page that receives email verification tokens
+	VerificationURL    string `mapstructure:"verification_url"`
+	VerificationSecret string `mapstructure:"verification_secret"`
+	VerificationExpiry string `mapstructure:"verification_expiry"`
+	VerificationResend string `mapstructure:"verification_resend"`
+	// UnverifiedPolicy is allow, read_only or block
+	UnverifiedPolicy string `mapstructure:"unverified_policy"`
+}
+
+type TwoFactorConfig struct {
+	Issuer       string `mapstructure:"issuer"`
+	ChallengeTTL string `mapstructure:"challenge_ttl"`
+	// RequiredRoles is a comma separated list of roles that must enable 2FA
+	RequiredRoles string `mapstructure:"required_roles"`
+}
+
+type LoginConfig struct {
+	// FreeAttempts failures per account (IPFreeAttempts per IP) are allowed
+	// before each attempt must wait BackoffBase, doubling up to BackoffMax
+	FreeAttempts     int    `mapstructure:"free_attempts"`
+	IPFreeAttempts   int    `mapstructure:"ip_free_attempts"`
+	BackoffBase      string `mapstructure:"backoff_base"`
+	BackoffMax       string `mapstructure:"backoff_max"`
+	LockoutThreshold int    `mapstructure:"lockout_threshold"`
+	LockoutDuration  string `mapstructure:"lockout_duration"`
+}
+
+// OIDCProviderConfig is read from OIDC_<NAME>_* for each name in OIDC_PROVIDERS
+type OIDCProviderConfig struct {
+	Name         string `mapstructure:"name"`
+	Issuer       string `mapstructure:"issuer"`
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+	RedirectURL  string `mapstructure:"redirect_url"`
+	// Scopes is space separated, like the OAuth scope parameter
+	Scopes string `mapstructure:"scopes"`
+	// DefaultRole is given to users created on their first login
+	DefaultRole string `mapstructure:"default_role"`
 }
@@ -0,0 +1,40 @@
+package configs
+
+import "testing"
+
+func TestCheckReleaseSecretsPreviousSecrets(t *testing.T) {
+	strong := func(prefix string) string {
+		return prefix + "-0123456789abcdefghijklmnopqrstuvwxyz"
+	}
+
+	tests := []struct {
+		name     string
+		previous string
+		wantErr  bool
+	}{
+		{name: "none", previous: ""},
+		{name: "strong", previous: strong("old-one") + ", " + strong("old-two")},
+		{name: "default access secret", previous: strong("old-one") + "," + defaultAccessSecret, wantErr: true},
+		{name: "default refresh secret", previous: defaultRefreshSecret, wantErr: true},
+		{name: "too short", previous: "short-secret", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				JWT: JWTConfig{
+					Algorithm:       "HS256",
+					AccessSecret:    strong("access"),
+					RefreshSecret:   strong("refresh"),
+					PreviousSecrets: tt.previous,
+				},
+				Account: AccountConfig{VerificationSecret: strong("verification")},
+			}
+
+			err := config.CheckReleaseSecrets()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckReleaseSecrets() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
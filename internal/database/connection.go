@@ -6,6 +6,7 @@ import (
 
 	"github.com/kyomel/blog-management/configs"
 	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/utils"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -66,6 +67,15 @@ func Connect(config *configs.DatabaseConfig) error {
 func Migrate() error {
 	log.Println("Starting database migration...")
 
+	// Accounts created before email verification existed count as verified
+	backfillEmailVerified := DB.Migrator().HasTable(&models.User{}) &&
+		!DB.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+
+	// Posts saved before word counts, reading times and outlines were derived
+	// have none until they are edited
+	backfillPostStats := DB.Migrator().HasTable(&models.Post{}) &&
+		!DB.Migrator().HasColumn(&models.Post{}, "Outline")
+
 	err := DB.AutoMigrate(
 		&models.User{},
 		&models.Category{},
@@ -73,6 +83,14 @@ func Migrate() error {
 		&models.Post{},
 		&models.MediaFile{},
 		&models.AuditLog{},
+		&models.SlugHistory{},
+		&models.PostViewDaily{},
+		&models.UserToken{},
+		&models.UserTOTP{},
+		&models.UserRecoveryCode{},
+		&models.UserIdentity{},
+		&models.OIDCLoginState{},
+		&models.PersonalAccessToken{},
 	)
 
 	if err != nil {
@@ -80,10 +98,62 @@ func Migrate() error {
 		return err
 	}
 
+	if backfillEmailVerified {
+		if err := DB.Exec("UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL").Error; err != nil {
+			log.Printf("Failed to mark existing users as verified: %v", err)
+			return err
+		}
+	}
+
+	if backfillPostStats {
+		if err := derivePostStats(); err != nil {
+			log.Printf("Failed to derive content of existing posts: %v", err)
+			return err
+		}
+	}
+
+	// Slug uniqueness used to include soft-deleted rows; the partial
+	// *_slug_live indexes replace these
+	for _, index := range []string{"idx_posts_slug", "idx_categories_slug", "idx_tags_slug"} {
+		if err := DB.Exec("DROP INDEX IF EXISTS " + index).Error; err != nil {
+			log.Printf("Failed to drop legacy index %s: %v", index, err)
+			return err
+		}
+	}
+
 	log.Println("Database migration completed successfully")
 	return nil
 }
 
+// derivePostStats derives the HTML, word count, reading time and outline of
+// posts that have no outline yet. Posts whose content cannot be rendered are
+// logged and left for their next edit.
+func derivePostStats() error {
+	var posts []models.Post
+	return DB.Select("id", "content", "content_format").
+		Where("outline IS NULL").
+		FindInBatches(&posts, 100, func(tx *gorm.DB, _ int) error {
+			for i := range posts {
+				post := &posts[i]
+				if err := utils.ApplyPostContent(post); err != nil {
+					log.Printf("Skipping content backfill of post %s: %v", post.ID, err)
+					continue
+				}
+
+				err := tx.Model(&models.Post{}).Where("id = ?", post.ID).UpdateColumns(map[string]interface{}{
+					"content_html": post.ContentHTML,
+					"word_count":   post.WordCount,
+					"reading_time": post.ReadingTime,
+					"outline":      post.Outline,
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
 func GetDB() *gorm.DB {
 	return DB
 }
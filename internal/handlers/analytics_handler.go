@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+type AnalyticsHandler struct {
+	analyticsService services.AnalyticsService
+}
+
+func NewAnalyticsHandler(analyticsService services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+func (h *AnalyticsHandler) GetSummary(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	summary, err := h.analyticsService.GetSummary(c.Request.Context(), c.Query("from"), c.Query("to"), limit)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidAnalyticsRange:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be YYYY-MM-DD dates, in order, at most 366 days apart"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch analytics"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *AnalyticsHandler) GetPostSeries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	series, err := h.analyticsService.GetPostSeries(c.Request.Context(), id, c.Query("from"), c.Query("to"), limit)
+	if err != nil {
+		switch err {
+		case services.ErrPostNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		case services.ErrInvalidAnalyticsRange:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be YYYY-MM-DD dates, in order, at most 366 days apart"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch post analytics"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
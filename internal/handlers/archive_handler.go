@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+type ArchiveHandler struct {
+	archiveService services.ArchiveService
+}
+
+func NewArchiveHandler(archiveService services.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService: archiveService,
+	}
+}
+
+func (h *ArchiveHandler) ListMonths(c *gin.Context) {
+	result, err := h.archiveService.GetMonths(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch archive"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *ArchiveHandler) ListPosts(c *gin.Context) {
+	year, yearErr := strconv.Atoi(c.Param("year"))
+	month, monthErr := strconv.Atoi(c.Param("month"))
+	if yearErr != nil || monthErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year or month"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	result, err := h.archiveService.GetPosts(c.Request.Context(), year, month, page, pageSize)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidArchivePeriod:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year or month"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+const (
+	// authorLatestPosts is how many posts an author profile shows
+	authorLatestPosts = 5
+	// authorFeedItems is how many posts an author feed carries
+	authorFeedItems = 20
+)
+
+// AuthorHandler serves the public author profiles and feeds
+type AuthorHandler struct {
+	authorService services.AuthorService
+	site          feedSite
+}
+
+// NewAuthorHandler creates the handler. siteTitle and siteURL name and link
+// the public frontend in feeds.
+func NewAuthorHandler(authorService services.AuthorService, siteTitle, siteURL string) *AuthorHandler {
+	return &AuthorHandler{
+		authorService: authorService,
+		site:          feedSite{Title: siteTitle, URL: siteURL},
+	}
+}
+
+func (h *AuthorHandler) ListAuthors(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	result, err := h.authorService.List(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch authors"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *AuthorHandler) GetAuthor(c *gin.Context) {
+	author, err := h.authorService.GetByUsername(c.Request.Context(), c.Param("username"), authorLatestPosts)
+	if err != nil {
+		h.authorError(c, err, "Failed to get author")
+		return
+	}
+
+	c.JSON(http.StatusOK, author)
+}
+
+// AuthorFeed serves the author's latest posts as RSS 2.0
+func (h *AuthorHandler) AuthorFeed(c *gin.Context) {
+	author, err := h.authorService.GetByUsername(c.Request.Context(), c.Param("username"), authorFeedItems)
+	if err != nil {
+		h.authorError(c, err, "Failed to build feed")
+		return
+	}
+
+	writeRSS(c, h.site.authorFeed(author))
+}
+
+func (h *AuthorHandler) authorError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrAuthorNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Author not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+type BackupHandler struct {
+	backupService services.BackupService
+}
+
+func NewBackupHandler(backupService services.BackupService) *BackupHandler {
+	return &BackupHandler{
+		backupService: backupService,
+	}
+}
+
+func (h *BackupHandler) Export(c *gin.Context) {
+	includePasswordHashes, _ := strconv.ParseBool(c.DefaultQuery("include_password_hashes", "false"))
+
+	archive, err := h.backupService.Export(c.Request.Context(), includePasswordHashes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create backup"})
+		return
+	}
+
+	fileName := fmt.Sprintf("backup-%s.json", archive.CreatedAt.Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.JSON(http.StatusOK, archive)
+}
+
+func (h *BackupHandler) Restore(c *gin.Context) {
+	var archive models.BackupArchive
+	if err := c.ShouldBindJSON(&archive); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid backup archive", "details": err.Error()})
+		return
+	}
+
+	result, err := h.backupService.Restore(c.Request.Context(), &archive)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnsupportedBackupVersion), errors.Is(err, services.ErrInvalidBackup):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid backup archive", "details": err.Error()})
+		case errors.Is(err, services.ErrRestoreTargetNotEmpty):
+			c.JSON(http.StatusConflict, gin.H{"error": "Backups can only be restored into a database without content"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore backup"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
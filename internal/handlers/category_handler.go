@@ -73,12 +73,16 @@ func (h *CategoryHandler) GetCategoryBySlug(c *gin.Context) {
 
 	category, err := h.categoryService.GetBySlug(c.Request.Context(), slug)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get category"})
+		if err == services.ErrCategoryNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get category"})
+		}
 		return
 	}
 
-	if category == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	if category.Slug != slug {
+		redirectToSlug(c, category.Slug)
 		return
 	}
 
@@ -96,9 +100,20 @@ func (h *CategoryHandler) ListCategories(c *gin.Context) {
 		pageSize = 10
 	}
 
-	result, err := h.categoryService.GetAll(c.Request.Context(), page, pageSize)
+	var result interface{}
+	var err error
+	if cursor, withTotal, ok := cursorQuery(c); ok {
+		result, err = h.categoryService.GetPage(c.Request.Context(), cursor, pageSize, withTotal)
+	} else {
+		result, err = h.categoryService.GetAll(c.Request.Context(), page, pageSize)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		switch err {
+		case services.ErrInvalidCursor:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		}
 		return
 	}
 
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+// rssFeed is an RSS 2.0 document. Item authors use dc:creator because the
+// RSS author element requires an email address.
+type rssFeed struct {
+	XMLName     xml.Name   `xml:"rss"`
+	Version     string     `xml:"version,attr"`
+	DCNamespace string     `xml:"xmlns:dc,attr"`
+	ContentNS   string     `xml:"xmlns:content,attr"`
+	Channel     rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Image         *rssImage `xml:"image,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssImage struct {
+	URL   string `xml:"url"`
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Creator     string   `xml:"dc:creator"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+	Content     rssCDATA `xml:"content:encoded"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssCDATA struct {
+	Value string `xml:",cdata"`
+}
+
+// feedSite describes the public frontend the feed links to
+type feedSite struct {
+	Title string
+	URL   string
+}
+
+func (s feedSite) link(segments ...string) string {
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.TrimSuffix(s.URL, "/") + "/" + strings.Join(segments, "/")
+}
+
+// authorFeed builds the feed of an author's latest posts
+func (s feedSite) authorFeed(author *models.AuthorProfileResponse) *rssFeed {
+	name := author.Fullname
+	if name == "" {
+		name = author.Username
+	}
+
+	channel := rssChannel{
+		Title:       name + " - " + s.Title,
+		Link:        s.link("authors", author.Username),
+		Description: author.Bio,
+		Items:       make([]rssItem, 0, len(author.LatestPosts)),
+	}
+	if channel.Description == "" {
+		channel.Description = "Posts by " + name + " on " + s.Title
+	}
+	if author.AvatarURL != "" {
+		channel.Image = &rssImage{URL: author.AvatarURL, Title: channel.Title, Link: channel.Link}
+	}
+
+	var lastBuild time.Time
+	for _, post := range author.LatestPosts {
+		item := rssItem{
+			Title:       post.Title,
+			Link:        s.link("posts", post.Slug),
+			GUID:        rssGUID{Value: post.ID.String(), IsPermaLink: false},
+			Creator:     name,
+			Description: post.Excerpt,
+			Content:     rssCDATA{Value: post.ContentHTML},
+		}
+		if post.PublishedAt != nil {
+			item.PubDate = post.PublishedAt.UTC().Format(time.RFC1123Z)
+			if post.PublishedAt.After(lastBuild) {
+				lastBuild = *post.PublishedAt
+			}
+		}
+		if post.Category != nil {
+			item.Categories = append(item.Categories, post.Category.Name)
+		}
+		for _, tag := range post.Tags {
+			item.Categories = append(item.Categories, tag.Name)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	if !lastBuild.IsZero() {
+		channel.LastBuildDate = lastBuild.UTC().Format(time.RFC1123Z)
+	}
+
+	return &rssFeed{
+		Version:     "2.0",
+		DCNamespace: "http://purl.org/dc/elements/1.1/",
+		ContentNS:   "http://purl.org/rss/1.0/modules/content/",
+		Channel:     channel,
+	}
+}
+
+// writeRSS renders feed with the XML declaration
+func writeRSS(c *gin.Context, feed *rssFeed) {
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render feed"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "application/rss+xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kyomel/blog-management/internal/middleware"
+	"github.com/kyomel/blog-management/internal/services/frontmatter"
+	"github.com/kyomel/blog-management/internal/services/importer"
+)
+
+type ImportHandler struct {
+	importer        *importer.Importer
+	markdownService *frontmatter.Service
+}
+
+func NewImportHandler(importer *importer.Importer, markdownService *frontmatter.Service) *ImportHandler {
+	return &ImportHandler{
+		importer:        importer,
+		markdownService: markdownService,
+	}
+}
+
+func (h *ImportHandler) Import(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse form"})
+		return
+	}
+
+	file, fileHeader, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+	defer file.Close()
+
+	format := c.PostForm("format")
+	if format == "" {
+		format, err = importer.DetectFormat(fileHeader.Filename)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Could not detect the export format, set format to wordpress or ghost"})
+			return
+		}
+	}
+
+	skipMedia, _ := strconv.ParseBool(c.DefaultPostForm("skip_media", "false"))
+
+	doc, err := importer.Parse(format, file)
+	if err != nil {
+		if err == importer.ErrUnknownFormat {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown format, expected wordpress or ghost"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export file", "details": err.Error()})
+		return
+	}
+
+	report, err := h.importer.Import(c.Request.Context(), doc, importer.Options{
+		DefaultAuthorID: claims.UserID,
+		BaseURL:         c.PostForm("base_url"),
+		SkipMedia:       skipMedia,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Import failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportMarkdown streams a zip archive with every post as a Markdown file
+func (h *ImportHandler) ExportMarkdown(c *gin.Context) {
+	fileName := fmt.Sprintf("posts-%s.zip", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Status(http.StatusOK)
+
+	// The status line has been sent, so a failure can only cut the archive short
+	if _, err := h.markdownService.ExportZip(c.Request.Context(), c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+// ImportMarkdown creates or updates posts from an uploaded zip of Markdown files
+func (h *ImportHandler) ImportMarkdown(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse form"})
+		return
+	}
+
+	file, fileHeader, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+	defer file.Close()
+
+	report, err := h.markdownService.ImportZip(c.Request.Context(), file, fileHeader.Size, claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid zip archive", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
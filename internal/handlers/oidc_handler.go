@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+func (h *AuthHandler) ListOIDCProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.authService.OIDCProviders()})
+}
+
+// OIDCLogin redirects the browser to the provider's login page
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	authURL, err := h.authService.OIDCAuthURL(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOIDCProviderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown login provider"})
+		default:
+			log.Printf("Failed to start login with %s: %v", c.Param("provider"), err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Login provider is unavailable"})
+		}
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback accepts the provider's redirect directly (GET with query
+// parameters) or relayed by a frontend (POST with a JSON body)
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	var req models.OIDCCallbackRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Error == "" && (req.Code == "" || req.State == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code and state are required"})
+		return
+	}
+
+	response, err := h.authService.OIDCCallback(c.Request.Context(), c.Param("provider"), req, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOIDCProviderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown login provider"})
+		case errors.Is(err, services.ErrOIDCDenied):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Login was cancelled at the provider"})
+		case errors.Is(err, services.ErrInvalidOIDCState):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Login expired or was already completed, please start again"})
+		case errors.Is(err, services.ErrOIDCLoginFailed):
+			log.Printf("Login with %s failed: %v", c.Param("provider"), err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Login with the provider failed"})
+		case errors.Is(err, services.ErrOIDCEmailRequired):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "The provider did not share an email address"})
+		case errors.Is(err, services.ErrOIDCEmailUnverified):
+			c.JSON(http.StatusConflict, gin.H{"error": "An account with this email exists; verify the email at the provider or log in with your password"})
+		case errors.Is(err, services.ErrUserNotActive):
+			c.JSON(http.StatusForbidden, gin.H{"error": "User account is not active"})
+		case errors.Is(err, services.ErrEmailNotVerified):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email address is not verified"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cursorQuery reports whether the request uses cursor pagination, which is
+// selected by the presence of the cursor parameter (empty for the first page).
+// The total count is only computed when with_total=true.
+func cursorQuery(c *gin.Context) (cursor string, withTotal bool, ok bool) {
+	cursor, ok = c.GetQuery("cursor")
+	withTotal, _ = strconv.ParseBool(c.Query("with_total"))
+	return cursor, withTotal, ok
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/middleware"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+type PersonalTokenHandler struct {
+	tokenService services.PersonalTokenService
+}
+
+func NewPersonalTokenHandler(tokenService services.PersonalTokenService) *PersonalTokenHandler {
+	return &PersonalTokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+func (h *PersonalTokenHandler) ListTokens(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	tokens, err := h.tokenService.List(c.Request.Context(), claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// CreateToken returns the new token in its response; it cannot be shown again
+func (h *PersonalTokenHandler) CreateToken(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.CreatePersonalTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name and scopes are required"})
+		return
+	}
+
+	response, err := h.tokenService.Create(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTokenScope):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "scopes": models.TokenScopeNames})
+		case errors.Is(err, services.ErrInvalidTokenExpiry):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Expiry must be in the future"})
+		case errors.Is(err, services.ErrTooManyPersonalTokens):
+			c.JSON(http.StatusConflict, gin.H{"error": "Token limit reached, revoke an unused token first"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *PersonalTokenHandler) RevokeToken(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	err = h.tokenService.Revoke(c.Request.Context(), claims.UserID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPersonalTokenNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
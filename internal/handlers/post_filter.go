@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+// parsePostFilter builds a post filter from the query string. Every invalid
+// parameter is reported in the returned map, keyed by parameter name.
+func parsePostFilter(c *gin.Context) (*models.PostFilter, map[string]string) {
+	filter := &models.PostFilter{Status: models.StatusPublished}
+	invalid := map[string]string{}
+
+	if status := c.Query("status"); status != "" {
+		switch models.PostStatus(status) {
+		case models.StatusDraft, models.StatusPublished, models.StatusArchived:
+			filter.Status = models.PostStatus(status)
+		default:
+			invalid["status"] = "must be one of draft, published, archived"
+		}
+	}
+
+	if ids, err := queryUUIDs(c, "category_id"); err != nil {
+		invalid["category_id"] = err.Error()
+	} else {
+		filter.CategoryIDs = ids
+	}
+	filter.CategorySlugs = queryList(c, "category")
+
+	filter.TagSlugs = queryList(c, "tag")
+	switch c.DefaultQuery("tag_match", "any") {
+	case "any":
+	case "all":
+		filter.TagMatchAll = true
+	default:
+		invalid["tag_match"] = "must be any or all"
+	}
+
+	if authorID := c.Query("author_id"); authorID != "" {
+		id, err := uuid.Parse(authorID)
+		if err != nil {
+			invalid["author_id"] = "must be a UUID"
+		} else {
+			filter.AuthorID = &id
+		}
+	}
+
+	if featured := c.Query("featured"); featured != "" {
+		isFeatured, err := strconv.ParseBool(featured)
+		if err != nil {
+			invalid["featured"] = "must be true or false"
+		} else {
+			filter.IsFeatured = &isFeatured
+		}
+	}
+
+	if hasImage := c.Query("has_featured_image"); hasImage != "" {
+		value, err := strconv.ParseBool(hasImage)
+		if err != nil {
+			invalid["has_featured_image"] = "must be true or false"
+		} else {
+			filter.HasFeaturedImage = &value
+		}
+	}
+
+	if ids, err := queryUUIDs(c, "exclude"); err != nil {
+		invalid["exclude"] = err.Error()
+	} else {
+		filter.ExcludeIDs = ids
+	}
+
+	if from := c.Query("published_from"); from != "" {
+		t, _, err := parseDateParam(from)
+		if err != nil {
+			invalid["published_from"] = err.Error()
+		} else {
+			filter.PublishedFrom = &t
+		}
+	}
+
+	// published_to is inclusive; a bare date covers the whole day
+	if to := c.Query("published_to"); to != "" {
+		t, dateOnly, err := parseDateParam(to)
+		if err != nil {
+			invalid["published_to"] = err.Error()
+		} else {
+			if dateOnly {
+				t = t.AddDate(0, 0, 1)
+			} else {
+				t = t.Add(time.Microsecond)
+			}
+			filter.PublishedBefore = &t
+		}
+	}
+
+	if filter.PublishedFrom != nil && filter.PublishedBefore != nil && !filter.PublishedFrom.Before(*filter.PublishedBefore) {
+		invalid["published_to"] = "must not be before published_from"
+	}
+
+	filter.Search = c.Query("search")
+
+	if minReadingTime := c.Query("min_reading_time"); minReadingTime != "" {
+		minutes, err := strconv.Atoi(minReadingTime)
+		if err != nil || minutes < 0 {
+			invalid["min_reading_time"] = "must be a non-negative integer"
+		} else {
+			filter.MinReadingTime = &minutes
+		}
+	}
+
+	if maxReadingTime := c.Query("max_reading_time"); maxReadingTime != "" {
+		minutes, err := strconv.Atoi(maxReadingTime)
+		if err != nil || minutes < 0 {
+			invalid["max_reading_time"] = "must be a non-negative integer"
+		} else {
+			filter.MaxReadingTime = &minutes
+		}
+	}
+
+	// include=author,tags loads only those relations; without it all are loaded
+	if _, ok := c.GetQuery("include"); ok {
+		include := &models.PostIncludes{}
+		for _, relation := range queryList(c, "include") {
+			switch relation {
+			case "author":
+				include.Author = true
+			case "category":
+				include.Category = true
+			case "tags":
+				include.Tags = true
+			default:
+				invalid["include"] = fmt.Sprintf("unknown relation %q; must be author, category or tags", relation)
+			}
+		}
+		filter.Include = include
+	}
+
+	// A leading "-" sorts in descending order, e.g. sort=-reading_time
+	if sort := c.Query("sort"); sort != "" {
+		filter.SortDesc = strings.HasPrefix(sort, "-")
+		filter.SortBy = strings.TrimPrefix(sort, "-")
+	}
+
+	return filter, invalid
+}
+
+// queryList collects a list parameter given either repeated (tag=a&tag=b) or
+// comma separated (tag=a,b)
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func queryUUIDs(c *gin.Context, key string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, value := range queryList(c, key) {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a UUID", value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseDateParam accepts RFC 3339 timestamps or YYYY-MM-DD dates (UTC) and
+// reports which form was given
+func parseDateParam(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, errors.New("must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+	}
+	return t, false, nil
+}
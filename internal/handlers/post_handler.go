@@ -13,11 +13,17 @@ import (
 
 type PostHandler struct {
 	postService services.PostService
+	viewCounter services.ViewCounter
+	// countryHeader names the request header holding the visitor's country,
+	// as set by a CDN or proxy; empty disables country tracking
+	countryHeader string
 }
 
-func NewPostHandler(postService services.PostService) *PostHandler {
+func NewPostHandler(postService services.PostService, viewCounter services.ViewCounter, countryHeader string) *PostHandler {
 	return &PostHandler{
-		postService: postService,
+		postService:   postService,
+		viewCounter:   viewCounter,
+		countryHeader: countryHeader,
 	}
 }
 
@@ -39,6 +45,8 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 		switch err {
 		case services.ErrPostSlugConflict:
 			c.JSON(http.StatusConflict, gin.H{"error": "A post with this slug already exists"})
+		case services.ErrInvalidContentFormat:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Content format must be markdown or html"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create post", "details": err.Error()})
 		}
@@ -65,10 +73,7 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 		return
 	}
 
-	// Increment view count asynchronously
-	go func() {
-		_ = h.postService.IncrementViewCount(c.Request.Context(), id)
-	}()
+	h.viewCounter.Record(id, h.pageView(c))
 
 	c.JSON(http.StatusOK, post)
 }
@@ -90,16 +95,28 @@ func (h *PostHandler) GetPostBySlug(c *gin.Context) {
 		return
 	}
 
-	// Increment view count asynchronously if post is found
-	if post != nil {
-		go func() {
-			_ = h.postService.IncrementViewCount(c.Request.Context(), post.ID)
-		}()
+	if post.Slug != slug {
+		redirectToSlug(c, post.Slug)
+		return
 	}
 
+	h.viewCounter.Record(post.ID, h.pageView(c))
+
 	c.JSON(http.StatusOK, post)
 }
 
+func (h *PostHandler) pageView(c *gin.Context) services.PageView {
+	view := services.PageView{
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Referrer:  c.Request.Referer(),
+	}
+	if h.countryHeader != "" {
+		view.Country = c.GetHeader(h.countryHeader)
+	}
+	return view
+}
+
 func (h *PostHandler) ListPosts(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
@@ -111,38 +128,28 @@ func (h *PostHandler) ListPosts(c *gin.Context) {
 		pageSize = 10
 	}
 
-	filter := &models.PostFilter{}
-
-	if status := c.Query("status"); status != "" {
-		filter.Status = models.PostStatus(status)
-	} else {
-		filter.Status = models.StatusPublished
-	}
-
-	if categoryID := c.Query("category_id"); categoryID != "" {
-		id, err := uuid.Parse(categoryID)
-		if err == nil {
-			filter.CategoryID = &id
-		}
-	}
-
-	if authorID := c.Query("author_id"); authorID != "" {
-		id, err := uuid.Parse(authorID)
-		if err == nil {
-			filter.AuthorID = &id
-		}
+	filter, invalid := parsePostFilter(c)
+	if len(invalid) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": invalid})
+		return
 	}
 
-	if featured := c.Query("featured"); featured == "true" {
-		isFeatured := true
-		filter.IsFeatured = &isFeatured
+	var result interface{}
+	var err error
+	if cursor, withTotal, ok := cursorQuery(c); ok {
+		result, err = h.postService.GetPage(c.Request.Context(), filter, cursor, pageSize, withTotal)
+	} else {
+		result, err = h.postService.GetAll(c.Request.Context(), filter, page, pageSize)
 	}
-
-	filter.Search = c.Query("search")
-
-	result, err := h.postService.GetAll(c.Request.Context(), filter, page, pageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+		switch err {
+		case services.ErrInvalidPostSort:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort field"})
+		case services.ErrInvalidCursor:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+		}
 		return
 	}
 
@@ -169,6 +176,8 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		case services.ErrPostSlugConflict:
 			c.JSON(http.StatusConflict, gin.H{"error": "A post with this slug already exists"})
+		case services.ErrInvalidContentFormat:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Content format must be markdown or html"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update post"})
 		}
@@ -217,3 +226,31 @@ func (h *PostHandler) PublishPost(c *gin.Context) {
 
 	c.JSON(http.StatusOK, post)
 }
+
+func (h *PostHandler) BulkPosts(c *gin.Context) {
+	var req models.BulkPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	result, err := h.postService.Bulk(c.Request.Context(), &req)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidBulkAction,
+			services.ErrBulkTargetRequired,
+			services.ErrBulkInvalidStatus,
+			services.ErrBulkTooManyPosts,
+			services.ErrBulkCategoryRequired,
+			services.ErrBulkTagsRequired,
+			services.ErrBulkCategoryNotFound,
+			services.ErrBulkTagNotFound:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bulk request", "details": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run bulk operation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
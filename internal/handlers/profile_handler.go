@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kyomel/blog-management/internal/middleware"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+const (
+	maxBioLength        = 1000
+	maxSocialLinkLength = 255
+)
+
+// ProfileHandler serves the endpoints users manage their own account with
+type ProfileHandler struct {
+	userService *services.UserService
+}
+
+func NewProfileHandler(userService *services.UserService) *ProfileHandler {
+	return &ProfileHandler{
+		userService: userService,
+	}
+}
+
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	user, err := h.userService.GetProfile(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.profileError(c, err, "Failed to get profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateProfile changes the name, username, bio and social links
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if invalid := validateProfile(&req); len(invalid) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile", "details": invalid})
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		h.profileError(c, err, "Failed to update profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ChangePassword sets a new password and signs out every session
+func (h *ProfileHandler) ChangePassword(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Current and new password are required"})
+		return
+	}
+
+	if len(req.NewPassword) < 8 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password must be at least 8 characters"})
+		return
+	}
+
+	if err := h.userService.ChangePassword(c.Request.Context(), claims.UserID, req, clientInfo(c)); err != nil {
+		h.profileError(c, err, "Failed to change password")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed. Please log in again."})
+}
+
+// ChangeEmail moves the account to a new address, which has to be verified
+// again
+func (h *ProfileHandler) ChangeEmail(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email and current password are required"})
+		return
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if !validEmail(req.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+		return
+	}
+
+	user, err := h.userService.ChangeEmail(c.Request.Context(), claims.UserID, req, clientInfo(c))
+	if err != nil {
+		h.profileError(c, err, "Failed to change email")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteAvatar removes the uploaded avatar
+func (h *ProfileHandler) DeleteAvatar(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.userService.RemoveAvatar(c.Request.Context(), claims.UserID); err != nil {
+		h.profileError(c, err, "Failed to delete avatar")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ProfileHandler) profileError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, services.ErrInvalidCredentials):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Current password is incorrect"})
+	case errors.Is(err, services.ErrEmailConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
+	case errors.Is(err, services.ErrUsernameConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// validateProfile trims the fields of req and returns the invalid ones
+func validateProfile(req *models.UpdateProfileRequest) map[string]string {
+	invalid := map[string]string{}
+
+	if req.Fullname != nil {
+		*req.Fullname = strings.TrimSpace(*req.Fullname)
+		if n := utf8.RuneCountInString(*req.Fullname); n < 2 || n > 100 {
+			invalid["fullname"] = "must be 2 to 100 characters"
+		}
+	}
+
+	if req.Username != nil {
+		*req.Username = strings.TrimSpace(*req.Username)
+		if !validUsername(*req.Username) {
+			invalid["username"] = "must be 3 to 50 lowercase letters, digits, '.', '_' or '-'"
+		}
+	}
+
+	if req.Bio != nil {
+		*req.Bio = strings.TrimSpace(*req.Bio)
+		if utf8.RuneCountInString(*req.Bio) > maxBioLength {
+			invalid["bio"] = "must be at most 1000 characters"
+		}
+	}
+
+	if req.SocialLinks != nil {
+		for name, link := range *req.SocialLinks {
+			link = strings.TrimSpace(link)
+			(*req.SocialLinks)[name] = link
+
+			switch {
+			case !slices.Contains(models.SocialLinkNames, name):
+				invalid["social_links."+name] = "must be one of " + strings.Join(models.SocialLinkNames, ", ")
+			case link != "" && !validProfileURL(link):
+				invalid["social_links."+name] = "must be an http or https URL of at most 255 characters"
+			}
+		}
+	}
+
+	return invalid
+}
+
+// validUsername allows only characters that are safe in URLs
+func validUsername(username string) bool {
+	if len(username) < 3 || len(username) > 50 {
+		return false
+	}
+	for _, r := range username {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '.' && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func validProfileURL(link string) bool {
+	if len(link) > maxSocialLinkLength {
+		return false
+	}
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validEmail accepts a bare address, without a display name
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
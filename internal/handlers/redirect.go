@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redirectToSlug answers a lookup by an old slug with a permanent redirect
+// to the same route using the entity's current slug
+func redirectToSlug(c *gin.Context, slug string) {
+	location := path.Join(path.Dir(c.Request.URL.Path), slug)
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Redirect(http.StatusMovedPermanently, location)
+}
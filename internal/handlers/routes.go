@@ -3,23 +3,54 @@ package handlers
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/kyomel/blog-management/internal/middleware"
+	"github.com/kyomel/blog-management/internal/models"
 )
 
 func RegisterRoutes(
 	router *gin.Engine,
 	authHandler *AuthHandler,
+	personalTokenHandler *PersonalTokenHandler,
+	profileHandler *ProfileHandler,
+	userHandler *UserHandler,
 	categoryHandler *CategoryHandler,
 	postHandler *PostHandler,
 	tagHandler *TagHandler,
+	authorHandler *AuthorHandler,
 	uploadHandler *UploadHandler,
+	trashHandler *TrashHandler,
+	importHandler *ImportHandler,
+	backupHandler *BackupHandler,
+	archiveHandler *ArchiveHandler,
+	analyticsHandler *AnalyticsHandler,
+	dashboardHandler *DashboardHandler,
 	authMiddleware *middleware.AuthMiddleware,
 ) {
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	auth := router.Group("/api/auth")
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
 		auth.POST("/refresh", authHandler.RefreshToken)
 		auth.POST("/logout", authHandler.Logout)
+		auth.POST("/forgot-password", authHandler.ForgotPassword)
+		auth.POST("/reset-password", authHandler.ResetPassword)
+		auth.POST("/verify-email", authHandler.VerifyEmail)
+		auth.POST("/resend-verification", authHandler.ResendVerification)
+		auth.POST("/2fa/verify", authHandler.VerifyTwoFactor)
+		auth.GET("/oidc", authHandler.ListOIDCProviders)
+		auth.GET("/oidc/:provider", authHandler.OIDCLogin)
+		auth.GET("/oidc/:provider/callback", authHandler.OIDCCallback)
+		auth.POST("/oidc/:provider/callback", authHandler.OIDCCallback)
+	}
+
+	twoFactor := router.Group("/api/auth/2fa")
+	twoFactor.Use(authMiddleware.AuthenticateTwoFactorSetup(), authMiddleware.RequireSession())
+	{
+		twoFactor.POST("/setup", authHandler.SetupTwoFactor)
+		twoFactor.POST("/confirm", authHandler.ConfirmTwoFactor)
+		twoFactor.POST("/disable", authHandler.DisableTwoFactor)
+		twoFactor.POST("/recovery-codes", authHandler.RegenerateRecoveryCodes)
 	}
 
 	categories := router.Group("/api/categories")
@@ -46,17 +77,48 @@ func RegisterRoutes(
 
 	posts.GET("/:id/tags", tagHandler.GetTagsByPost)
 
+	authors := router.Group("/api/authors")
+	{
+		authors.GET("", authorHandler.ListAuthors)
+		authors.GET("/:username", authorHandler.GetAuthor)
+		authors.GET("/:username/feed", authorHandler.AuthorFeed)
+	}
+
+	archive := router.Group("/api/archive")
+	{
+		archive.GET("", archiveHandler.ListMonths)
+		archive.GET("/:year/:month", archiveHandler.ListPosts)
+	}
+
+	// Users who cannot make changes before verifying their email must still
+	// be able to fix the address
+	router.PUT("/api/profile/email", authMiddleware.AuthenticateUnverified(), authMiddleware.RequireSession(), profileHandler.ChangeEmail)
+
 	api := router.Group("/api")
 	api.Use(authMiddleware.Authenticate())
 	{
 		profile := api.Group("/profile")
 		{
-			profile.POST("/avatar", uploadHandler.UploadAvatar)
+			profile.GET("", profileHandler.GetProfile)
+			profile.PATCH("", authMiddleware.RequireSession(), profileHandler.UpdateProfile)
+			profile.PUT("/password", authMiddleware.RequireSession(), profileHandler.ChangePassword)
+			profile.POST("/avatar", authMiddleware.RequireScope(models.ScopeMediaWrite), uploadHandler.UploadAvatar)
+			profile.DELETE("/avatar", authMiddleware.RequireScope(models.ScopeMediaWrite), profileHandler.DeleteAvatar)
 		}
+
+		tokens := api.Group("/tokens")
+		tokens.Use(authMiddleware.RequireSession())
+		{
+			tokens.GET("", personalTokenHandler.ListTokens)
+			tokens.POST("", personalTokenHandler.CreateToken)
+			tokens.DELETE("/:id", personalTokenHandler.RevokeToken)
+		}
+
 		admin := api.Group("/admin")
 		admin.Use(authMiddleware.RequireRole("admin"))
 		{
 			adminCategories := admin.Group("/categories")
+			adminCategories.Use(authMiddleware.RequireScope(models.ScopeCategoriesWrite))
 			{
 				adminCategories.POST("", categoryHandler.CreateCategory)
 				adminCategories.PUT("/:id", categoryHandler.UpdateCategory)
@@ -64,23 +126,65 @@ func RegisterRoutes(
 			}
 
 			adminPosts := admin.Group("/posts")
+			adminPosts.Use(authMiddleware.RequireScope(models.ScopePostsWrite))
 			{
 				adminPosts.POST("", postHandler.CreatePost)
+				adminPosts.POST("/bulk", postHandler.BulkPosts)
 				adminPosts.PUT("/:id", postHandler.UpdatePost)
 				adminPosts.DELETE("/:id", postHandler.DeletePost)
 				adminPosts.PUT("/:id/publish", postHandler.PublishPost)
 			}
 
 			adminTags := admin.Group("/tags")
+			adminTags.Use(authMiddleware.RequireScope(models.ScopeTagsWrite))
 			{
 				adminTags.POST("", tagHandler.CreateTag)
 				adminTags.PUT("/:id", tagHandler.UpdateTag)
 				adminTags.DELETE("/:id", tagHandler.DeleteTag)
 			}
 
-			admin.GET("/dashboard", func(c *gin.Context) {
-				c.JSON(200, gin.H{"message": "Admin dashboard"})
-			})
+			requireAdminScope := authMiddleware.RequireScope(models.ScopeAdmin)
+
+			adminTrash := admin.Group("/trash")
+			adminTrash.Use(requireAdminScope)
+			{
+				adminTrash.GET("/:type", trashHandler.ListTrash)
+				adminTrash.POST("/:type/:id/restore", trashHandler.RestoreItem)
+				adminTrash.DELETE("/:type/:id", trashHandler.PurgeItem)
+			}
+
+			admin.POST("/import", requireAdminScope, importHandler.Import)
+			admin.POST("/import/markdown", requireAdminScope, importHandler.ImportMarkdown)
+			admin.GET("/export/markdown", requireAdminScope, importHandler.ExportMarkdown)
+
+			adminBackup := admin.Group("/backup")
+			adminBackup.Use(requireAdminScope)
+			{
+				adminBackup.GET("", backupHandler.Export)
+				adminBackup.POST("/restore", backupHandler.Restore)
+			}
+
+			requireAnalyticsScope := authMiddleware.RequireScope(models.ScopeAnalyticsRead)
+
+			adminAnalytics := admin.Group("/analytics")
+			adminAnalytics.Use(requireAnalyticsScope)
+			{
+				adminAnalytics.GET("", analyticsHandler.GetSummary)
+				adminAnalytics.GET("/posts/:id", analyticsHandler.GetPostSeries)
+			}
+
+			admin.GET("/dashboard", requireAnalyticsScope, dashboardHandler.GetStats)
+
+			adminUsers := admin.Group("/users")
+			adminUsers.Use(requireAdminScope)
+			{
+				adminUsers.GET("", userHandler.ListUsers)
+				adminUsers.GET("/:id", userHandler.GetUser)
+				adminUsers.PUT("/:id/role", userHandler.UpdateUserRole)
+				adminUsers.PUT("/:id/status", userHandler.UpdateUserStatus)
+				adminUsers.DELETE("/:id", userHandler.DeleteUser)
+				adminUsers.POST("/:id/unlock", authHandler.UnlockAccount)
+			}
 		}
 	}
 }
@@ -82,6 +82,11 @@ func (h *TagHandler) GetTagBySlug(c *gin.Context) {
 		return
 	}
 
+	if tag.Slug != slug {
+		redirectToSlug(c, tag.Slug)
+		return
+	}
+
 	c.JSON(http.StatusOK, tag)
 }
 
@@ -96,9 +101,20 @@ func (h *TagHandler) ListTags(c *gin.Context) {
 		pageSize = 10
 	}
 
-	result, err := h.tagService.GetAll(c.Request.Context(), page, pageSize)
+	var result interface{}
+	var err error
+	if cursor, withTotal, ok := cursorQuery(c); ok {
+		result, err = h.tagService.GetPage(c.Request.Context(), cursor, pageSize, withTotal)
+	} else {
+		result, err = h.tagService.GetAll(c.Request.Context(), page, pageSize)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		switch err {
+		case services.ErrInvalidCursor:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		}
 		return
 	}
 
@@ -190,11 +206,18 @@ func (h *TagHandler) GetPostsByTag(c *gin.Context) {
 		pageSize = 10
 	}
 
-	result, err := h.tagService.GetPostsByTagID(c.Request.Context(), tagID, page, pageSize)
+	var result interface{}
+	if cursor, withTotal, ok := cursorQuery(c); ok {
+		result, err = h.tagService.GetPostsPageByTagID(c.Request.Context(), tagID, cursor, pageSize, withTotal)
+	} else {
+		result, err = h.tagService.GetPostsByTagID(c.Request.Context(), tagID, page, pageSize)
+	}
 	if err != nil {
 		switch err {
 		case services.ErrTagNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		case services.ErrInvalidCursor:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts for tag"})
 		}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+type TrashHandler struct {
+	trashService services.TrashService
+}
+
+func NewTrashHandler(trashService services.TrashService) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+func (h *TrashHandler) ListTrash(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	entityType := models.TrashEntityType(c.Param("type"))
+
+	result, err := h.trashService.GetAll(c.Request.Context(), entityType, page, pageSize)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidTrashType:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown trash type"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *TrashHandler) RestoreItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	entityType := models.TrashEntityType(c.Param("type"))
+
+	err = h.trashService.Restore(c.Request.Context(), entityType, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidTrashType:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown trash type"})
+		case services.ErrTrashItemNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found in trash"})
+		case services.ErrTrashSlugConflict:
+			c.JSON(http.StatusConflict, gin.H{"error": "Another item now uses this slug"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore item"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item restored"})
+}
+
+func (h *TrashHandler) PurgeItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	entityType := models.TrashEntityType(c.Param("type"))
+
+	err = h.trashService.Purge(c.Request.Context(), entityType, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidTrashType:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown trash type"})
+		case services.ErrTrashItemNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found in trash"})
+		case services.ErrTrashItemInUse:
+			c.JSON(http.StatusConflict, gin.H{"error": "Item is still referenced by other content"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge item"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
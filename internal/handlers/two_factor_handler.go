@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kyomel/blog-management/internal/middleware"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+// VerifyTwoFactor completes a login that answered with two_factor_required
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.ChallengeToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Challenge token is required"})
+		return
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code or recovery code is required"})
+		return
+	}
+
+	response, err := h.authService.VerifyTwoFactor(c.Request.Context(), req, clientInfo(c))
+	if err != nil {
+		var retry *services.RetryError
+		if errors.As(err, &retry) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retry.RetryAfter.Seconds()))))
+		}
+
+		switch {
+		case errors.Is(err, services.ErrInvalidChallenge), errors.Is(err, services.ErrTwoFactorNotEnabled):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge, please log in again"})
+		case errors.Is(err, services.ErrInvalidTwoFactorCode):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		case errors.Is(err, services.ErrUserNotActive):
+			c.JSON(http.StatusForbidden, gin.H{"error": "User account is not active"})
+		case errors.Is(err, services.ErrAccountLocked):
+			c.JSON(http.StatusLocked, gin.H{"error": "Account is temporarily locked after too many failed attempts"})
+		case errors.Is(err, services.ErrTooManyAttempts):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed attempts, try again later"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	response, err := h.authService.SetupTwoFactor(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.twoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) ConfirmTwoFactor(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
+		return
+	}
+
+	response, err := h.authService.ConfirmTwoFactor(c.Request.Context(), claims.UserID, req.Code)
+	if err != nil {
+		h.twoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Code == "" && req.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code or recovery code is required"})
+		return
+	}
+
+	if err := h.authService.DisableTwoFactor(c.Request.Context(), claims.UserID, req, clientInfo(c)); err != nil {
+		h.twoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	claims, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(c.Request.Context(), claims.UserID, req.Code, clientInfo(c))
+	if err != nil {
+		h.twoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *AuthHandler) twoFactorError(c *gin.Context, err error) {
+	var retry *services.RetryError
+	if errors.As(err, &retry) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retry.RetryAfter.Seconds()))))
+	}
+
+	switch {
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+	case errors.Is(err, services.ErrTwoFactorNotEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+	case errors.Is(err, services.ErrTwoFactorNotSetUp):
+		c.JSON(http.StatusConflict, gin.H{"error": "Start two-factor setup first"})
+	case errors.Is(err, services.ErrTwoFactorRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for your role"})
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+	case errors.Is(err, services.ErrAccountLocked):
+		c.JSON(http.StatusLocked, gin.H{"error": "Account is temporarily locked after too many failed attempts"})
+	case errors.Is(err, services.ErrTooManyAttempts):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed attempts, try again later"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Two-factor request failed"})
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"errors"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -17,15 +18,37 @@ const (
 
 type AuthMiddleware struct {
 	authService services.AuthService
+	// unverifiedReadOnly limits users without a verified email to safe methods
+	unverifiedReadOnly bool
 }
 
-func NewAuthMiddleware(authService services.AuthService) *AuthMiddleware {
+func NewAuthMiddleware(authService services.AuthService, unverifiedReadOnly bool) *AuthMiddleware {
 	return &AuthMiddleware{
-		authService: authService,
+		authService:        authService,
+		unverifiedReadOnly: unverifiedReadOnly,
 	}
 }
 
+// Authenticate accepts session JWTs and personal access tokens. Routes open
+// to personal access tokens should also use RequireScope, and the others
+// RequireSession.
 func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
+	return m.authenticate(false, false)
+}
+
+// AuthenticateTwoFactorSetup also accepts users whose role requires
+// two-factor authentication but who have not enrolled yet, so they can enrol
+func (m *AuthMiddleware) AuthenticateTwoFactorSetup() gin.HandlerFunc {
+	return m.authenticate(true, false)
+}
+
+// AuthenticateUnverified lets users without a verified email make changes
+// even under the read-only policy, so they can correct a mistyped address
+func (m *AuthMiddleware) AuthenticateUnverified() gin.HandlerFunc {
+	return m.authenticate(false, true)
+}
+
+func (m *AuthMiddleware) authenticate(allowTwoFactorSetup, allowUnverified bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -47,10 +70,12 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := m.authService.ValidateToken(tokenString)
+		claims, err := m.authService.ValidateToken(c.Request.Context(), tokenString)
 		if err != nil {
 			if errors.Is(err, utils.ErrExpiredToken) {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has expired"})
+			} else if errors.Is(err, services.ErrEmailNotVerified) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Email address is not verified"})
 			} else {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			}
@@ -58,6 +83,18 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
+		if claims.TwoFactorSetupRequired && !allowTwoFactorSetup {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Enable two-factor authentication to continue"})
+			c.Abort()
+			return
+		}
+
+		if m.unverifiedReadOnly && !allowUnverified && !claims.EmailVerified && !isSafeMethod(c.Request.Method) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Verify your email address to make changes"})
+			c.Abort()
+			return
+		}
+
 		c.Set(UserContextKey, claims)
 		c.Next()
 	}
@@ -99,6 +136,48 @@ func (m *AuthMiddleware) RequireRole(roles ...models.UserRole) gin.HandlerFunc {
 	}
 }
 
+// RequireScope limits personal access tokens to those granted scope. Session
+// tokens always pass; RequireRole still applies to both.
+func (m *AuthMiddleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetUserFromContext(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if claims.Scopes != nil && !slices.Contains(claims.Scopes, scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Token is missing the " + scope + " scope"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession rejects personal access tokens, for endpoints that manage
+// the account itself
+func (m *AuthMiddleware) RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetUserFromContext(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if claims.Scopes != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Personal access tokens cannot be used here"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 func GetUserFromContext(c *gin.Context) (*utils.JWTClaims, bool) {
 	claims, exists := c.Get(UserContextKey)
 	if !exists {
@@ -108,3 +187,7 @@ func GetUserFromContext(c *gin.Context) (*utils.JWTClaims, bool) {
 	jwtClaims, ok := claims.(*utils.JWTClaims)
 	return jwtClaims, ok
 }
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeviceClass string
+
+const (
+	DeviceDesktop DeviceClass = "desktop"
+	DeviceMobile  DeviceClass = "mobile"
+	DeviceTablet  DeviceClass = "tablet"
+)
+
+// PostViewDaily aggregates the views of a post per day and visitor origin.
+// Empty referrer host and country mean direct traffic and unknown country.
+type PostViewDaily struct {
+	PostID       uuid.UUID   `json:"post_id" gorm:"type:uuid;primaryKey"`
+	Day          time.Time   `json:"day" gorm:"type:date;primaryKey;index"`
+	ReferrerHost string      `json:"referrer_host" gorm:"type:varchar(255);primaryKey"`
+	Country      string      `json:"country" gorm:"type:varchar(2);primaryKey"`
+	Device       DeviceClass `json:"device" gorm:"type:varchar(10);primaryKey"`
+	Views        int         `json:"views" gorm:"not null;default:0"`
+}
+
+func (PostViewDaily) TableName() string {
+	return "post_view_daily"
+}
+
+// ViewBucket identifies one row of PostViewDaily; Day is formatted YYYY-MM-DD
+type ViewBucket struct {
+	PostID       uuid.UUID
+	Day          string
+	ReferrerHost string
+	Country      string
+	Device       DeviceClass
+}
+
+type AnalyticsTopPost struct {
+	PostID uuid.UUID `json:"post_id"`
+	Title  string    `json:"title"`
+	Slug   string    `json:"slug"`
+	Views  int       `json:"views"`
+}
+
+type AnalyticsTopCategory struct {
+	CategoryID uuid.UUID `json:"category_id"`
+	Name       string    `json:"name"`
+	Slug       string    `json:"slug"`
+	Views      int       `json:"views"`
+}
+
+type AnalyticsReferrer struct {
+	// Host is empty for direct visits
+	Host  string `json:"host"`
+	Views int    `json:"views"`
+}
+
+// AnalyticsSummary covers the days From to To inclusive
+type AnalyticsSummary struct {
+	From          string                 `json:"from"`
+	To            string                 `json:"to"`
+	TotalViews    int                    `json:"total_views"`
+	TopPosts      []AnalyticsTopPost     `json:"top_posts"`
+	TopCategories []AnalyticsTopCategory `json:"top_categories"`
+	TopReferrers  []AnalyticsReferrer    `json:"top_referrers"`
+}
+
+type DailyViews struct {
+	Day   string `json:"day"`
+	Views int    `json:"views"`
+}
+
+// PostViewSeries lists a post's views for every day from From to To
+type PostViewSeries struct {
+	PostID       uuid.UUID           `json:"post_id"`
+	From         string              `json:"from"`
+	To           string              `json:"to"`
+	TotalViews   int                 `json:"total_views"`
+	Days         []DailyViews        `json:"days"`
+	TopReferrers []AnalyticsReferrer `json:"top_referrers"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// ArchiveMonth counts the published posts of one calendar month
+type ArchiveMonth struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Count int `json:"count"`
+}
+
+// ArchiveResponse lists archive months, newest first, in the blog's timezone
+type ArchiveResponse struct {
+	Timezone string         `json:"timezone"`
+	Months   []ArchiveMonth `json:"months"`
+}
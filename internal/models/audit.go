@@ -13,18 +13,30 @@ const (
 	ActionCreate AuditAction = "create"
 	ActionUpdate AuditAction = "update"
 	ActionDelete AuditAction = "delete"
+
+	ActionLogin       AuditAction = "login"
+	ActionLoginFailed AuditAction = "login_failed"
+	ActionUnlock      AuditAction = "unlock"
 )
 
+// AuditLog records a change or a login attempt. UserID is nil for failed
+// logins with an unknown email.
 type AuditLog struct {
 	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID    uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
+	UserID    *uuid.UUID     `json:"user_id" gorm:"type:uuid"`
 	TableName string         `json:"table_name" gorm:"not null"`
 	Action    AuditAction    `json:"action" gorm:"type:varchar(20);not null"`
 	OldValues datatypes.JSON `json:"old_values" gorm:"type:jsonb"`
 	NewValues datatypes.JSON `json:"new_values" gorm:"type:jsonb"`
 	IPAddress string         `json:"ip_address"`
 	UserAgent string         `json:"user_agent"`
-	CreatedAt time.Time      `json:"created_at"`
+	CreatedAt time.Time      `json:"created_at" gorm:"index"`
 
 	User User `json:"user" gorm:"foreignKey:UserID"`
 }
+
+// ClientInfo identifies where a request came from, for the audit log
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
@@ -0,0 +1,27 @@
+package models
+
+// AuthorResponse is the public profile of a user with published posts. It
+// leaves out the email, role and account state.
+type AuthorResponse struct {
+	Username    string      `json:"username"`
+	Fullname    string      `json:"fullname"`
+	Bio         string      `json:"bio"`
+	AvatarURL   string      `json:"avatar_url"`
+	SocialLinks SocialLinks `json:"social_links"`
+	// PostCount counts published posts only
+	PostCount int64 `json:"post_count"`
+}
+
+// AuthorProfileResponse is an author with their most recent posts
+type AuthorProfileResponse struct {
+	AuthorResponse
+	LatestPosts []*PostResponse `json:"latest_posts"`
+}
+
+type PaginatedAuthorResponse struct {
+	Data       []*AuthorResponse `json:"data"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+	TotalPages int               `json:"total_pages"`
+}
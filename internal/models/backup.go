@@ -0,0 +1,146 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BackupVersion is bumped whenever the archive layout changes incompatibly.
+// Version 2 added users' email_verified_at, bio and social_links.
+const BackupVersion = 2
+
+// BackupArchive is a full JSON dump of the blog. IDs are those of the source
+// database; a restore assigns new ones and rewrites every reference.
+type BackupArchive struct {
+	Version                int                 `json:"version"`
+	CreatedAt              time.Time           `json:"created_at"`
+	IncludesPasswordHashes bool                `json:"includes_password_hashes"`
+	Users                  []BackupUser        `json:"users"`
+	Categories             []BackupCategory    `json:"categories"`
+	Tags                   []BackupTag         `json:"tags"`
+	Posts                  []BackupPost        `json:"posts"`
+	PostTags               []BackupPostTag     `json:"post_tags"`
+	MediaFiles             []BackupMediaFile   `json:"media_files"`
+	AuditLogs              []BackupAuditLog    `json:"audit_logs"`
+	SlugHistory            []BackupSlugHistory `json:"slug_history"`
+}
+
+type BackupUser struct {
+	ID           uuid.UUID   `json:"id"`
+	Fullname     string      `json:"fullname"`
+	Email        string      `json:"email"`
+	Username     string      `json:"username"`
+	PasswordHash string      `json:"password_hash,omitempty"`
+	Role         UserRole    `json:"role"`
+	AvatarURL    string      `json:"avatar_url"`
+	IsActive     bool        `json:"is_active"`
+	Bio          string      `json:"bio"`
+	SocialLinks  SocialLinks `json:"social_links,omitempty"`
+	// EmailVerifiedAt is missing from version 1 archives, which predate email
+	// verification
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+}
+
+type BackupCategory struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Slug        string     `json:"slug"`
+	Description string     `json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type BackupTag struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	Color     string     `json:"color"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type BackupPost struct {
+	ID               uuid.UUID       `json:"id"`
+	AuthorID         uuid.UUID       `json:"author_id"`
+	CategoryID       uuid.UUID       `json:"category_id"`
+	Title            string          `json:"title"`
+	Slug             string          `json:"slug"`
+	Content          string          `json:"content"`
+	ContentFormat    ContentFormat   `json:"content_format"`
+	ContentHTML      string          `json:"content_html"`
+	WordCount        int             `json:"word_count"`
+	ReadingTime      int             `json:"reading_time"`
+	Outline          json.RawMessage `json:"outline,omitempty"`
+	Excerpt          string          `json:"excerpt"`
+	FeaturedImageURL string          `json:"featured_image_url"`
+	Status           PostStatus      `json:"status"`
+	ViewCount        int             `json:"view_count"`
+	IsFeatured       bool            `json:"is_featured"`
+	Metadata         json.RawMessage `json:"metadata,omitempty"`
+	PublishedAt      *time.Time      `json:"published_at,omitempty"`
+	CreatedAt        *time.Time      `json:"created_at"`
+	UpdatedAt        *time.Time      `json:"updated_at"`
+	DeletedAt        *time.Time      `json:"deleted_at,omitempty"`
+}
+
+type BackupPostTag struct {
+	PostID uuid.UUID `json:"post_id"`
+	TagID  uuid.UUID `json:"tag_id"`
+}
+
+type BackupMediaFile struct {
+	ID                 uuid.UUID       `json:"id"`
+	UserID             uuid.UUID       `json:"user_id"`
+	OriginalName       string          `json:"original_name"`
+	FileName           string          `json:"file_name"`
+	FilePath           string          `json:"file_path"`
+	CloudinaryPublicID string          `json:"cloudinary_public_id"`
+	MimeType           string          `json:"mime_type"`
+	FileSize           int64           `json:"file_size"`
+	Metadata           json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+	DeletedAt          *time.Time      `json:"deleted_at,omitempty"`
+}
+
+type BackupAuditLog struct {
+	ID        uuid.UUID       `json:"id"`
+	UserID    *uuid.UUID      `json:"user_id,omitempty"`
+	TableName string          `json:"table_name"`
+	Action    AuditAction     `json:"action"`
+	OldValues json.RawMessage `json:"old_values,omitempty"`
+	NewValues json.RawMessage `json:"new_values,omitempty"`
+	IPAddress string          `json:"ip_address"`
+	UserAgent string          `json:"user_agent"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type BackupSlugHistory struct {
+	EntityType SlugEntityType `json:"entity_type"`
+	Slug       string         `json:"slug"`
+	EntityID   uuid.UUID      `json:"entity_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// RestoreResult counts the rows written by a restore
+type RestoreResult struct {
+	Users         int `json:"users"`
+	ExistingUsers int `json:"existing_users"`
+	// UsersWithoutPassword were restored from an archive without password
+	// hashes and must reset their password before they can log in
+	UsersWithoutPassword int `json:"users_without_password"`
+	Categories           int `json:"categories"`
+	Tags                 int `json:"tags"`
+	Posts                int `json:"posts"`
+	PostTags             int `json:"post_tags"`
+	MediaFiles           int `json:"media_files"`
+	AuditLogs            int `json:"audit_logs"`
+	SlugHistory          int `json:"slug_history"`
+}
@@ -7,12 +7,12 @@ import (
 )
 
 type Category struct {
-	ID          uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:gen_random_uuid()"`
-	Name        string    `json:"name" gorm:"type:varchar(255);uniqueIndex;not null"`
-	Slug        string    `json:"slug" gorm:"type:varchar(255);uniqueIndex;not null"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primarykey;default:gen_random_uuid()"`
+	Name        string     `json:"name" gorm:"type:varchar(255);uniqueIndex;not null"`
+	Slug        string     `json:"slug" gorm:"type:varchar(255);not null;uniqueIndex:idx_categories_slug_live,where:deleted_at IS NULL"`
+	Description string     `json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at,omitempty" gorm:"index"`
 
 	Posts []Post `json:"posts,omitempty" gorm:"foreignKey:CategoryID"`
@@ -20,7 +20,7 @@ type Category struct {
 
 type CreateCategoryRequest struct {
 	Name        string `json:"name" validate:"required,min=3,max=255"`
-	Slug        string `json:"slug" validate:"required,slug,max=255"`
+	Slug        string `json:"slug,omitempty" validate:"omitempty,slug,max=255"`
 	Description string `json:"description,omitempty"`
 }
 
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Cursor marks the last row of a keyset-paginated page. Sort and Desc record
+// the ordering it was issued for, so it cannot be replayed against another.
+type Cursor struct {
+	Sort string    `json:"s"`
+	Desc bool      `json:"d,omitempty"`
+	Key  string    `json:"k"`
+	ID   uuid.UUID `json:"i"`
+}
+
+type CursorPostResponse struct {
+	Posts      []*PostResponse `json:"posts"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	PageSize   int             `json:"page_size"`
+	Total      *int            `json:"total,omitempty"`
+}
+
+type CursorCategoryResponse struct {
+	Data       []*CategoryResponse `json:"data"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+	PageSize   int                 `json:"page_size"`
+	Total      *int                `json:"total,omitempty"`
+}
+
+type CursorTagResponse struct {
+	Data       []*TagResponse `json:"data"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	PageSize   int            `json:"page_size"`
+	Total      *int           `json:"total,omitempty"`
+}
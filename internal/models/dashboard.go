@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DashboardStats summarises the state of the blog for the admin dashboard
+type DashboardStats struct {
+	PostsByStatus map[PostStatus]int `json:"posts_by_status"`
+	// StaleDrafts are drafts not updated for StaleDraftDays
+	StaleDrafts    int `json:"stale_drafts"`
+	StaleDraftDays int `json:"stale_draft_days"`
+	// ScheduledPosts are published posts whose publication date is in the future
+	ScheduledPosts int `json:"scheduled_posts"`
+	// RecentSignups are users created in the last SignupDays
+	RecentSignups int          `json:"recent_signups"`
+	SignupDays    int          `json:"signup_days"`
+	MediaFiles    int          `json:"media_files"`
+	MediaBytes    int64        `json:"media_bytes"`
+	RecentAudit   []AuditEntry `json:"recent_audit"`
+	GeneratedAt   time.Time    `json:"generated_at"`
+}
+
+type AuditEntry struct {
+	ID        uuid.UUID   `json:"id"`
+	UserID    *uuid.UUID  `json:"user_id"`
+	Username  string      `json:"username"`
+	TableName string      `json:"table_name"`
+	Action    AuditAction `json:"action"`
+	CreatedAt time.Time   `json:"created_at"`
+}
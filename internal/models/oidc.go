@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to an account at an external OpenID provider
+type UserIdentity struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:gen_random_uuid()"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	// Provider and Subject (the provider's stable user ID) identify the account
+	Provider    string     `json:"provider" gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject     string     `json:"subject" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email       string     `json:"email" gorm:"type:varchar(255)"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// OIDCLoginState remembers an authorization request until its callback.
+// Only the hash of the state parameter is stored.
+type OIDCLoginState struct {
+	StateHash    string    `gorm:"type:varchar(64);primarykey"`
+	Provider     string    `gorm:"type:varchar(50);not null"`
+	Nonce        string    `gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time
+}
+
+// OIDCCallbackRequest carries the parameters the provider redirected with
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" form:"code"`
+	State string `json:"state" form:"state"`
+	// Error is set by the provider when the user denied access
+	Error            string `json:"error" form:"error"`
+	ErrorDescription string `json:"error_description" form:"error_description"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scopes a personal access token can be granted. Each covers a group of
+// endpoints; the user's role is still checked as well.
+const (
+	ScopePostsWrite      = "posts:write"
+	ScopeCategoriesWrite = "categories:write"
+	ScopeTagsWrite       = "tags:write"
+	ScopeMediaWrite      = "media:write"
+	ScopeAnalyticsRead   = "analytics:read"
+	// ScopeAdmin covers the remaining admin endpoints: trash, import,
+	// export, backup and user administration
+	ScopeAdmin = "admin"
+)
+
+var TokenScopeNames = []string{
+	ScopePostsWrite,
+	ScopeCategoriesWrite,
+	ScopeTagsWrite,
+	ScopeMediaWrite,
+	ScopeAnalyticsRead,
+	ScopeAdmin,
+}
+
+// TokenScopes is stored as a space separated list
+type TokenScopes []string
+
+func (s TokenScopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+func (s *TokenScopes) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	case nil:
+		*s = TokenScopes{}
+	default:
+		return fmt.Errorf("cannot scan %T into TokenScopes", value)
+	}
+	return nil
+}
+
+// PersonalAccessToken is a long-lived API token for scripts. Only the SHA-256
+// hash of the token is stored; Prefix is kept to tell tokens apart.
+type PersonalAccessToken struct {
+	ID         uuid.UUID   `json:"id" gorm:"type:uuid;primarykey;default:gen_random_uuid()"`
+	UserID     uuid.UUID   `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name       string      `json:"name" gorm:"type:varchar(100);not null"`
+	Prefix     string      `json:"prefix" gorm:"type:varchar(20);not null"`
+	TokenHash  string      `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Scopes     TokenScopes `json:"scopes" gorm:"type:text;not null"`
+	ExpiresAt  *time.Time  `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type CreatePersonalTokenRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	// ExpiresAt is optional; tokens without it never expire
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreatePersonalTokenResponse is the only time the token itself is returned
+type CreatePersonalTokenResponse struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
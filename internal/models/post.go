@@ -12,8 +12,33 @@ type PostFilter struct {
 	AuthorID   *uuid.UUID
 	IsFeatured *bool
 	Search     string
-	Limit      int
-	Offset     int
+	// Posts in any of these categories, given by ID or slug
+	CategoryIDs   []uuid.UUID
+	CategorySlugs []string
+	TagSlugs      []string
+	// TagMatchAll requires every tag in TagSlugs instead of any of them
+	TagMatchAll bool
+	// PublishedFrom is inclusive, PublishedBefore exclusive
+	PublishedFrom    *time.Time
+	PublishedBefore  *time.Time
+	HasFeaturedImage *bool
+	ExcludeIDs       []uuid.UUID
+	// Reading time bounds in minutes
+	MinReadingTime *int
+	MaxReadingTime *int
+	SortBy         string
+	SortDesc       bool
+	Limit          int
+	Offset         int
+	// Include selects the relations loaded with each post; nil loads them all
+	Include *PostIncludes
+}
+
+// PostIncludes selects the relations loaded with each post of a listing
+type PostIncludes struct {
+	Author   bool
+	Category bool
+	Tags     bool
 }
 
 type PostStatus string
@@ -24,50 +49,73 @@ const (
 	StatusArchived  PostStatus = "archived"
 )
 
+type ContentFormat string
+
+const (
+	FormatMarkdown ContentFormat = "markdown"
+	FormatHTML     ContentFormat = "html"
+)
+
+// PostHeading is a single entry in a post's table of contents
+type PostHeading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"`
+}
+
 type CreatePostRequest struct {
-	AuthorID         uuid.UUID   `json:"author_id" validate:"required"`
-	CategoryID       uuid.UUID   `json:"category_id" validate:"required"`
-	Title            string      `json:"title" validate:"required"`
-	Slug             string      `json:"slug" validate:"required"`
-	Content          string      `json:"content" validate:"required"`
-	Excerpt          string      `json:"excerpt"`
-	FeaturedImageURL string      `json:"featured_image_url"`
-	Status           PostStatus  `json:"status" validate:"required,oneof=draft published archived"`
-	IsFeatured       bool        `json:"is_featured"`
-	Metadata         []byte      `json:"metadata,omitempty"`
-	TagIDs           []uuid.UUID `json:"tag_ids,omitempty"`
+	AuthorID         uuid.UUID     `json:"author_id" validate:"required"`
+	CategoryID       uuid.UUID     `json:"category_id" validate:"required"`
+	Title            string        `json:"title" validate:"required"`
+	Slug             string        `json:"slug,omitempty"`
+	Content          string        `json:"content" validate:"required"`
+	ContentFormat    ContentFormat `json:"content_format" validate:"omitempty,oneof=markdown html"`
+	Excerpt          string        `json:"excerpt"`
+	FeaturedImageURL string        `json:"featured_image_url"`
+	Status           PostStatus    `json:"status" validate:"required,oneof=draft published archived"`
+	IsFeatured       bool          `json:"is_featured"`
+	Metadata         []byte        `json:"metadata,omitempty"`
+	TagIDs           []uuid.UUID   `json:"tag_ids,omitempty"`
+	PublishedAt      *time.Time    `json:"published_at,omitempty"`
 }
 
 type UpdatePostRequest struct {
-	CategoryID       uuid.UUID   `json:"category_id,omitempty"`
-	Title            string      `json:"title,omitempty"`
-	Slug             string      `json:"slug,omitempty"`
-	Content          string      `json:"content,omitempty"`
-	Excerpt          string      `json:"excerpt,omitempty"`
-	FeaturedImageURL string      `json:"featured_image_url,omitempty"`
-	Status           PostStatus  `json:"status,omitempty" validate:"omitempty,oneof=draft published archived"`
-	IsFeatured       *bool       `json:"is_featured,omitempty"`
-	Metadata         []byte      `json:"metadata,omitempty"`
-	TagIDs           []uuid.UUID `json:"tag_ids,omitempty"`
+	CategoryID       uuid.UUID     `json:"category_id,omitempty"`
+	Title            string        `json:"title,omitempty"`
+	Slug             string        `json:"slug,omitempty"`
+	Content          string        `json:"content,omitempty"`
+	ContentFormat    ContentFormat `json:"content_format,omitempty" validate:"omitempty,oneof=markdown html"`
+	Excerpt          string        `json:"excerpt,omitempty"`
+	FeaturedImageURL string        `json:"featured_image_url,omitempty"`
+	Status           PostStatus    `json:"status,omitempty" validate:"omitempty,oneof=draft published archived"`
+	IsFeatured       *bool         `json:"is_featured,omitempty"`
+	Metadata         []byte        `json:"metadata,omitempty"`
+	TagIDs           []uuid.UUID   `json:"tag_ids,omitempty"`
+	PublishedAt      *time.Time    `json:"published_at,omitempty"`
 }
 
 // PostResponse represents the response for a post
 type PostResponse struct {
-	ID               uuid.UUID   `json:"id"`
-	AuthorID         uuid.UUID   `json:"author_id"`
-	CategoryID       uuid.UUID   `json:"category_id"`
-	Title            string      `json:"title"`
-	Slug             string      `json:"slug"`
-	Content          string      `json:"content"`
-	Excerpt          string      `json:"excerpt"`
-	FeaturedImageURL string      `json:"featured_image_url"`
-	Status           PostStatus  `json:"status"`
-	ViewCount        int         `json:"view_count"`
-	IsFeatured       bool        `json:"is_featured"`
-	PublishedAt      *time.Time  `json:"published_at,omitempty"`
-	CreatedAt        *time.Time   `json:"created_at"`
-	UpdatedAt        *time.Time   `json:"updated_at"`
-	Metadata         interface{} `json:"metadata,omitempty"`
+	ID               uuid.UUID     `json:"id"`
+	AuthorID         uuid.UUID     `json:"author_id"`
+	CategoryID       uuid.UUID     `json:"category_id"`
+	Title            string        `json:"title"`
+	Slug             string        `json:"slug"`
+	Content          string        `json:"content"`
+	ContentFormat    ContentFormat `json:"content_format"`
+	ContentHTML      string        `json:"content_html"`
+	WordCount        int           `json:"word_count"`
+	ReadingTime      int           `json:"reading_time"`
+	Outline          []PostHeading `json:"outline,omitempty"`
+	Excerpt          string        `json:"excerpt"`
+	FeaturedImageURL string        `json:"featured_image_url"`
+	Status           PostStatus    `json:"status"`
+	ViewCount        int           `json:"view_count"`
+	IsFeatured       bool          `json:"is_featured"`
+	PublishedAt      *time.Time    `json:"published_at,omitempty"`
+	CreatedAt        *time.Time    `json:"created_at"`
+	UpdatedAt        *time.Time    `json:"updated_at"`
+	Metadata         interface{}   `json:"metadata,omitempty"`
 
 	Author   *User     `json:"author,omitempty"`
 	Category *Category `json:"category,omitempty"`
@@ -83,22 +131,27 @@ type PaginatedPostResponse struct {
 }
 
 type Post struct {
-	ID               uuid.UUID  `json:"id" gorm:"type:uuid;primarykey;default:gen_random_uuid()"`
-	AuthorID         uuid.UUID  `json:"author_id" gorm:"type:uuid;not null"`
-	CategoryID       uuid.UUID  `json:"category_id" gorm:"type:uuid;not null"`
-	Title            string     `json:"title" gorm:"type:varchar(255);not null"`
-	Slug             string     `json:"slug" gorm:"type:varchar(255);uniqueIndex;not null"`
-	Content          string     `json:"content" gorm:"type:text"`
-	Excerpt          string     `json:"excerpt" gorm:"type:text"`
-	FeaturedImageURL string     `json:"featured_image_url"`
-	Status           PostStatus `json:"status" gorm:"type:varchar(20);default:draft"`
-	ViewCount        int        `json:"view_count" gorm:"type:int;default:0"`
-	IsFeatured       bool       `json:"is_featured" gorm:"type:boolean;default:false"`
-	PublishedAt      *time.Time `json:"published_at"`
-	CreatedAt        *time.Time `json:"created_at"`
-	UpdatedAt        *time.Time `json:"updated_at"`
-	DeletedAt        *time.Time `json:"deleted_at,omitempty" gorm:"index"`
-	Metadata         []byte     `json:"metadata,omitempty"`
+	ID               uuid.UUID     `json:"id" gorm:"type:uuid;primarykey;default:gen_random_uuid();index:idx_posts_created_at_id,priority:2"`
+	AuthorID         uuid.UUID     `json:"author_id" gorm:"type:uuid;not null"`
+	CategoryID       uuid.UUID     `json:"category_id" gorm:"type:uuid;not null"`
+	Title            string        `json:"title" gorm:"type:varchar(255);not null"`
+	Slug             string        `json:"slug" gorm:"type:varchar(255);not null;uniqueIndex:idx_posts_slug_live,where:deleted_at IS NULL"`
+	Content          string        `json:"content" gorm:"type:text"`
+	ContentFormat    ContentFormat `json:"content_format" gorm:"type:varchar(20);default:html"`
+	ContentHTML      string        `json:"content_html" gorm:"type:text;default:''"`
+	WordCount        int           `json:"word_count" gorm:"type:int;default:0"`
+	ReadingTime      int           `json:"reading_time" gorm:"type:int;default:0;index"`
+	Outline          []byte        `json:"outline,omitempty" gorm:"type:jsonb"`
+	Excerpt          string        `json:"excerpt" gorm:"type:text"`
+	FeaturedImageURL string        `json:"featured_image_url"`
+	Status           PostStatus    `json:"status" gorm:"type:varchar(20);default:draft"`
+	ViewCount        int           `json:"view_count" gorm:"type:int;default:0"`
+	IsFeatured       bool          `json:"is_featured" gorm:"type:boolean;default:false"`
+	PublishedAt      *time.Time    `json:"published_at" gorm:"index:idx_posts_published_at,where:status = 'published' AND deleted_at IS NULL"`
+	CreatedAt        *time.Time    `json:"created_at" gorm:"index:idx_posts_created_at_id,priority:1,where:deleted_at IS NULL"`
+	UpdatedAt        *time.Time    `json:"updated_at"`
+	DeletedAt        *time.Time    `json:"deleted_at,omitempty" gorm:"index"`
+	Metadata         []byte        `json:"metadata,omitempty"`
 
 	Author   *User     `json:"author,omitempty" gorm:"foreignKey:AuthorID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	Category *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
@@ -0,0 +1,50 @@
+package models
+
+import "github.com/google/uuid"
+
+type BulkPostAction string
+
+const (
+	BulkActionPublish     BulkPostAction = "publish"
+	BulkActionArchive     BulkPostAction = "archive"
+	BulkActionDelete      BulkPostAction = "delete"
+	BulkActionRestore     BulkPostAction = "restore"
+	BulkActionSetCategory BulkPostAction = "set_category"
+	BulkActionAddTags     BulkPostAction = "add_tags"
+	BulkActionRemoveTags  BulkPostAction = "remove_tags"
+	BulkActionFeature     BulkPostAction = "feature"
+	BulkActionUnfeature   BulkPostAction = "unfeature"
+)
+
+// BulkPostFilter selects posts for a bulk operation when no IDs are given
+type BulkPostFilter struct {
+	Status     PostStatus `json:"status,omitempty"`
+	CategoryID *uuid.UUID `json:"category_id,omitempty"`
+	AuthorID   *uuid.UUID `json:"author_id,omitempty"`
+	IsFeatured *bool      `json:"is_featured,omitempty"`
+	Search     string     `json:"search,omitempty"`
+}
+
+type BulkPostRequest struct {
+	IDs        []uuid.UUID     `json:"ids,omitempty"`
+	Filter     *BulkPostFilter `json:"filter,omitempty"`
+	Action     BulkPostAction  `json:"action"`
+	CategoryID *uuid.UUID      `json:"category_id,omitempty"`
+	TagIDs     []uuid.UUID     `json:"tag_ids,omitempty"`
+	DryRun     bool            `json:"dry_run"`
+}
+
+type BulkPostItemResult struct {
+	ID      uuid.UUID `json:"id"`
+	Success bool      `json:"success"`
+	Error   string    `json:"error,omitempty"`
+}
+
+type BulkPostResponse struct {
+	Action    BulkPostAction       `json:"action"`
+	DryRun    bool                 `json:"dry_run"`
+	Matched   int                  `json:"matched"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Results   []BulkPostItemResult `json:"results"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// SocialLinkNames are the accepted keys of SocialLinks
+var SocialLinkNames = []string{"website", "github", "twitter", "mastodon", "linkedin", "youtube", "instagram", "facebook"}
+
+// SocialLinks maps a network from SocialLinkNames to a profile URL. It is
+// stored as a JSON object.
+type SocialLinks map[string]string
+
+func (l SocialLinks) Value() (driver.Value, error) {
+	if l == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(map[string]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *SocialLinks) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case nil:
+		*l = SocialLinks{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into SocialLinks", value)
+	}
+
+	links := SocialLinks{}
+	if err := json.Unmarshal(data, &links); err != nil {
+		return err
+	}
+	*l = links
+	return nil
+}
+
+// UpdateProfileRequest changes only the fields that are present. SocialLinks
+// replaces all links; an empty URL removes a link.
+type UpdateProfileRequest struct {
+	Fullname    *string      `json:"fullname"`
+	Username    *string      `json:"username"`
+	Bio         *string      `json:"bio"`
+	SocialLinks *SocialLinks `json:"social_links"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ChangeEmailRequest struct {
+	Email           string `json:"email" binding:"required"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
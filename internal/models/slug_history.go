@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SlugEntityType string
+
+const (
+	SlugEntityPost     SlugEntityType = "post"
+	SlugEntityCategory SlugEntityType = "category"
+	SlugEntityTag      SlugEntityType = "tag"
+)
+
+// SlugHistory records a slug that an entity used before it was renamed
+type SlugHistory struct {
+	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primarykey;default:gen_random_uuid()"`
+	EntityType SlugEntityType `json:"entity_type" gorm:"type:varchar(20);not null;uniqueIndex:idx_slug_history_entity_slug"`
+	Slug       string         `json:"slug" gorm:"type:varchar(255);not null;uniqueIndex:idx_slug_history_entity_slug"`
+	EntityID   uuid.UUID      `json:"entity_id" gorm:"type:uuid;not null;index"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+func (SlugHistory) TableName() string {
+	return "slug_history"
+}
@@ -7,10 +7,10 @@ import (
 )
 
 type Tag struct {
-	ID        uuid.UUID `json:"id" gorm:"type:uuid;primarykey;default:gen_random_uuid()"`
-	Name      string    `json:"name" gorm:"type:varchar(255);uniqueIndex;not null"`
-	Slug      string    `json:"slug" gorm:"type:varchar(255);uniqueIndex;not null"`
-	Color     string    `json:"color"`
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primarykey;default:gen_random_uuid()"`
+	Name      string     `json:"name" gorm:"type:varchar(255);uniqueIndex;not null"`
+	Slug      string     `json:"slug" gorm:"type:varchar(255);not null;uniqueIndex:idx_tags_slug_live,where:deleted_at IS NULL"`
+	Color     string     `json:"color"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"index"`
@@ -20,7 +20,7 @@ type Tag struct {
 
 type CreateTagRequest struct {
 	Name  string `json:"name" binding:"required"`
-	Slug  string `json:"slug" binding:"required"`
+	Slug  string `json:"slug,omitempty"`
 	Color string `json:"color" binding:"required"`
 }
 
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TrashEntityType string
+
+const (
+	TrashPosts      TrashEntityType = "posts"
+	TrashCategories TrashEntityType = "categories"
+	TrashTags       TrashEntityType = "tags"
+	TrashUsers      TrashEntityType = "users"
+)
+
+// TrashItem is a soft-deleted row of any trashable entity type
+type TrashItem struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      TrashEntityType `json:"type"`
+	Name      string          `json:"name"`
+	Slug      string          `json:"slug,omitempty"`
+	DeletedAt time.Time       `json:"deleted_at"`
+}
+
+type PaginatedTrashResponse struct {
+	Data       []*TrashItem `json:"data"`
+	Total      int64        `json:"total"`
+	Page       int          `json:"page"`
+	PageSize   int          `json:"page_size"`
+	TotalPages int          `json:"total_pages"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTOTP holds a user's TOTP secret. It is pending until ConfirmedAt is set
+// by a first valid code; only then is two-factor login enabled.
+type UserTOTP struct {
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;primarykey"`
+	Secret string    `json:"-" gorm:"type:varchar(64);not null"`
+	// LastUsedStep is the last accepted time step; older or equal steps are
+	// rejected so a code cannot be replayed
+	LastUsedStep int64      `json:"-" gorm:"not null;default:0"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (UserTOTP) TableName() string {
+	return "user_totp"
+}
+
+// UserRecoveryCode is a one-time two-factor code for when the authenticator
+// is lost. Only the SHA-256 hash of the code is stored.
+type UserRecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primarykey;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret string `json:"secret"`
+	// OTPAuthURI is meant to be shown as a QR code
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorCodeRequest proves possession of the second factor. RecoveryCode
+// is only accepted where noted.
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// TwoFactorLoginRequest exchanges a login challenge for a token pair
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type RecoveryCodesResponse struct {
+	// RecoveryCodes are shown only once
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorEnabledResponse carries a fresh token pair, since enabling two-factor
+// authentication signs out every other session
+type TwoFactorEnabledResponse struct {
+	AuthResponse
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -22,9 +22,24 @@ type User struct {
 	Role         UserRole  `json:"role" gorm:"type:varchar(20);default:user"`
 	AvatarURL    string    `json:"avatar_url"`
 	IsActive     bool      `json:"is_active" gorm:"type:boolean;default:true"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-	DeletedAt    *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	// TokenVersion is embedded in issued JWTs; bumping it revokes them all
+	TokenVersion int `json:"-" gorm:"not null;default:0"`
+	// EmailVerifiedAt is nil until the user follows the emailed verification link
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// VerificationSentAt throttles resending the verification email
+	VerificationSentAt *time.Time `json:"-"`
+	// TwoFactorEnabledAt is set once a TOTP secret has been confirmed
+	TwoFactorEnabledAt *time.Time `json:"-"`
+	// FailedLoginAttempts counts wrong passwords since the last successful
+	// login or lockout; LastFailedLoginAt and LockedUntil throttle guessing
+	FailedLoginAttempts int         `json:"-" gorm:"not null;default:0"`
+	LastFailedLoginAt   *time.Time  `json:"-"`
+	LockedUntil         *time.Time  `json:"locked_until,omitempty"`
+	Bio                 string      `json:"bio" gorm:"type:text;not null;default:''"`
+	SocialLinks         SocialLinks `json:"social_links" gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedAt           time.Time   `json:"created_at"`
+	UpdatedAt           time.Time   `json:"updated_at"`
+	DeletedAt           *time.Time  `json:"deleted_at,omitempty" gorm:"index"`
 
 	Posts      []Post      `json:"posts,omitempty" gorm:"foreignKey:AuthorID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	MediaFiles []MediaFile `json:"media_files,omitempty" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
@@ -44,11 +59,36 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
 type AuthResponse struct {
-	User         UserResponse `json:"user"`
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token"`
-	ExpiresIn    int64        `json:"expires_in"`
+	User UserResponse `json:"user"`
+	// Tokens are left out when unverified users may not log in
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	// TwoFactorRequired means ChallengeToken must be exchanged with a code at
+	// /api/auth/2fa/verify; no tokens are issued yet
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+	// TwoFactorSetupRequired means the user's role requires two-factor
+	// authentication and the tokens only work for enrolling
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required,omitempty"`
 }
 
 type UserResponse struct {
@@ -59,5 +99,10 @@ type UserResponse struct {
 	Role      UserRole  `json:"role"`
 	AvatarURL string    `json:"avatar_url"`
 	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
+	// EmailVerified reports whether the user has confirmed their email address
+	EmailVerified    bool        `json:"email_verified"`
+	TwoFactorEnabled bool        `json:"two_factor_enabled"`
+	Bio              string      `json:"bio"`
+	SocialLinks      SocialLinks `json:"social_links"`
+	CreatedAt        time.Time   `json:"created_at"`
 }
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// UserFilter narrows the admin user list
+type UserFilter struct {
+	// Search matches part of the email or username
+	Search   string
+	Role     UserRole
+	IsActive *bool
+}
+
+// AdminUserResponse is a user as shown to admins
+type AdminUserResponse struct {
+	UserResponse
+	// PostCount excludes posts in the trash
+	PostCount   int64      `json:"post_count"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type PaginatedUserResponse struct {
+	Data       []*AdminUserResponse `json:"data"`
+	Total      int64                `json:"total"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"page_size"`
+	TotalPages int                  `json:"total_pages"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role UserRole `json:"role" binding:"required"`
+}
+
+type UpdateUserStatusRequest struct {
+	IsActive *bool `json:"is_active" binding:"required"`
+}
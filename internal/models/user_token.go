@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserTokenPurpose string
+
+const (
+	TokenPasswordReset UserTokenPurpose = "password_reset"
+	// TokenTwoFactorChallenge is issued by a password login and exchanged
+	// together with a TOTP code for a token pair
+	TokenTwoFactorChallenge UserTokenPurpose = "two_factor_challenge"
+)
+
+// UserToken is a single-use token emailed to a user. Only the SHA-256 hash of
+// the token is stored.
+type UserToken struct {
+	ID        uuid.UUID        `json:"id" gorm:"type:uuid;primarykey;default:gen_random_uuid()"`
+	UserID    uuid.UUID        `json:"user_id" gorm:"type:uuid;not null;index"`
+	Purpose   UserTokenPurpose `json:"purpose" gorm:"type:varchar(30);not null"`
+	TokenHash string           `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time        `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time       `json:"used_at,omitempty"`
+	// Attempts counts failed uses, for tokens that allow retrying
+	Attempts  int       `json:"-" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"bytes"
+	"database/sql"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+type AnalyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository(db *sql.DB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// SaveViews adds the buffered views to the daily aggregates and to each post's
+// view count in one transaction
+func (r *AnalyticsRepository) SaveViews(buckets map[models.ViewBucket]int) error {
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	// A fixed order keeps concurrent flushes from deadlocking on row locks
+	keys := make([]models.ViewBucket, 0, len(buckets))
+	for key := range buckets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if c := bytes.Compare(keys[i].PostID[:], keys[j].PostID[:]); c != 0 {
+			return c < 0
+		}
+		a, b := keys[i], keys[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.ReferrerHost != b.ReferrerHost {
+			return a.ReferrerHost < b.ReferrerHost
+		}
+		if a.Country != b.Country {
+			return a.Country < b.Country
+		}
+		return a.Device < b.Device
+	})
+
+	var (
+		postIDs   = make([]string, len(keys))
+		days      = make([]string, len(keys))
+		referrers = make([]string, len(keys))
+		countries = make([]string, len(keys))
+		devices   = make([]string, len(keys))
+		views     = make([]int64, len(keys))
+	)
+	for i, key := range keys {
+		postIDs[i] = key.PostID.String()
+		days[i] = key.Day
+		referrers[i] = key.ReferrerHost
+		countries[i] = key.Country
+		devices[i] = string(key.Device)
+		views[i] = int64(buckets[key])
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+        WITH v AS (
+            SELECT * FROM unnest($1::uuid[], $2::date[], $3::text[], $4::text[], $5::text[], $6::int8[])
+                AS v(post_id, day, referrer_host, country, device, views)
+        )
+        INSERT INTO post_view_daily (post_id, day, referrer_host, country, device, views)
+        SELECT v.post_id, v.day, v.referrer_host, v.country, v.device, v.views
+        FROM v
+        JOIN posts p ON p.id = v.post_id
+        ON CONFLICT (post_id, day, referrer_host, country, device)
+        DO UPDATE SET views = post_view_daily.views + EXCLUDED.views`,
+		postIDs, days, referrers, countries, devices, views)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+        UPDATE posts p
+        SET view_count = p.view_count + v.views
+        FROM (
+            SELECT post_id, SUM(views) AS views
+            FROM unnest($1::uuid[], $2::int8[]) AS v(post_id, views)
+            GROUP BY post_id
+        ) v
+        WHERE p.id = v.post_id`,
+		postIDs, views)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// TotalViews sums all views between the days from and to inclusive
+func (r *AnalyticsRepository) TotalViews(from, to string) (int, error) {
+	var total int
+	err := r.db.QueryRow(`
+        SELECT COALESCE(SUM(views), 0)
+        FROM post_view_daily
+        WHERE day BETWEEN $1::date AND $2::date`, from, to).Scan(&total)
+	return total, err
+}
+
+// TopPosts returns the most viewed live posts between the days from and to
+func (r *AnalyticsRepository) TopPosts(from, to string, limit int) ([]models.AnalyticsTopPost, error) {
+	rows, err := r.db.Query(`
+        SELECT p.id, p.title, p.slug, SUM(d.views) AS views
+        FROM post_view_daily d
+        JOIN posts p ON p.id = d.post_id
+        WHERE d.day BETWEEN $1::date AND $2::date AND p.deleted_at IS NULL
+        GROUP BY p.id, p.title, p.slug
+        ORDER BY views DESC, p.id
+        LIMIT $3`, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []models.AnalyticsTopPost{}
+	for rows.Next() {
+		var post models.AnalyticsTopPost
+		if err := rows.Scan(&post.PostID, &post.Title, &post.Slug, &post.Views); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	return posts, rows.Err()
+}
+
+// TopCategories ranks categories by the views of their posts
+func (r *AnalyticsRepository) TopCategories(from, to string, limit int) ([]models.AnalyticsTopCategory, error) {
+	rows, err := r.db.Query(`
+        SELECT c.id, c.name, c.slug, SUM(d.views) AS views
+        FROM post_view_daily d
+        JOIN posts p ON p.id = d.post_id
+        JOIN categories c ON c.id = p.category_id
+        WHERE d.day BETWEEN $1::date AND $2::date AND p.deleted_at IS NULL AND c.deleted_at IS NULL
+        GROUP BY c.id, c.name, c.slug
+        ORDER BY views DESC, c.id
+        LIMIT $3`, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.AnalyticsTopCategory{}
+	for rows.Next() {
+		var category models.AnalyticsTopCategory
+		if err := rows.Scan(&category.CategoryID, &category.Name, &category.Slug, &category.Views); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// TopReferrers ranks referrer hosts, optionally for a single post
+func (r *AnalyticsRepository) TopReferrers(postID *uuid.UUID, from, to string, limit int) ([]models.AnalyticsReferrer, error) {
+	rows, err := r.db.Query(`
+        SELECT referrer_host, SUM(views) AS views
+        FROM post_view_daily
+        WHERE day BETWEEN $1::date AND $2::date AND ($3::uuid IS NULL OR post_id = $3::uuid)
+        GROUP BY referrer_host
+        ORDER BY views DESC, referrer_host
+        LIMIT $4`, from, to, postID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	referrers := []models.AnalyticsReferrer{}
+	for rows.Next() {
+		var referrer models.AnalyticsReferrer
+		if err := rows.Scan(&referrer.Host, &referrer.Views); err != nil {
+			return nil, err
+		}
+		referrers = append(referrers, referrer)
+	}
+	return referrers, rows.Err()
+}
+
+// PostDailyViews returns the post's views for every day from from to to,
+// including days without views
+func (r *AnalyticsRepository) PostDailyViews(postID uuid.UUID, from, to string) ([]models.DailyViews, error) {
+	rows, err := r.db.Query(`
+        SELECT to_char(s.day, 'YYYY-MM-DD'), COALESCE(SUM(d.views), 0)
+        FROM generate_series($2::date, $3::date, interval '1 day') AS s(day)
+        LEFT JOIN post_view_daily d ON d.day = s.day::date AND d.post_id = $1
+        GROUP BY s.day
+        ORDER BY s.day`, postID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []models.DailyViews{}
+	for rows.Next() {
+		var day models.DailyViews
+		if err := rows.Scan(&day.Day, &day.Views); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, rows.Err()
+}
@@ -0,0 +1,421 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+var (
+	ErrRestoreTargetNotEmpty = errors.New("restore target already contains content")
+	ErrBackupBrokenReference = errors.New("backup references a row that is not in the archive")
+)
+
+type BackupRepository struct {
+	db *sql.DB
+}
+
+func NewBackupRepository(db *sql.DB) *BackupRepository {
+	return &BackupRepository{db: db}
+}
+
+// Dump reads every table, soft-deleted rows included, from one snapshot so
+// the archive is consistent even while the blog is being written to
+func (r *BackupRepository) Dump(ctx context.Context, includePasswordHashes bool) (*models.BackupArchive, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	archive := &models.BackupArchive{
+		Version:                models.BackupVersion,
+		IncludesPasswordHashes: includePasswordHashes,
+		Users:                  []models.BackupUser{},
+		Categories:             []models.BackupCategory{},
+		Tags:                   []models.BackupTag{},
+		Posts:                  []models.BackupPost{},
+		PostTags:               []models.BackupPostTag{},
+		MediaFiles:             []models.BackupMediaFile{},
+		AuditLogs:              []models.BackupAuditLog{},
+		SlugHistory:            []models.BackupSlugHistory{},
+	}
+
+	if err := tx.QueryRowContext(ctx, `SELECT NOW()`).Scan(&archive.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	err = queryRows(ctx, tx, `
+        SELECT id, COALESCE(fullname, ''), email, username, password_hash, COALESCE(role, 'user'),
+               COALESCE(avatar_url, ''), COALESCE(is_active, true), email_verified_at,
+               COALESCE(bio, ''), social_links, created_at, updated_at, deleted_at
+        FROM users ORDER BY created_at, id`,
+		func(rows *sql.Rows) error {
+			var u models.BackupUser
+			if err := rows.Scan(&u.ID, &u.Fullname, &u.Email, &u.Username, &u.PasswordHash, &u.Role,
+				&u.AvatarURL, &u.IsActive, &u.EmailVerifiedAt, &u.Bio, &u.SocialLinks, &u.CreatedAt, &u.UpdatedAt, &u.DeletedAt); err != nil {
+				return err
+			}
+			if !includePasswordHashes {
+				u.PasswordHash = ""
+			}
+			archive.Users = append(archive.Users, u)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump users: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `
+        SELECT id, name, slug, COALESCE(description, ''), created_at, updated_at, deleted_at
+        FROM categories ORDER BY created_at, id`,
+		func(rows *sql.Rows) error {
+			var c models.BackupCategory
+			if err := rows.Scan(&c.ID, &c.Name, &c.Slug, &c.Description, &c.CreatedAt, &c.UpdatedAt, &c.DeletedAt); err != nil {
+				return err
+			}
+			archive.Categories = append(archive.Categories, c)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump categories: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `
+        SELECT id, name, slug, COALESCE(color, ''), created_at, updated_at, deleted_at
+        FROM tags ORDER BY created_at, id`,
+		func(rows *sql.Rows) error {
+			var t models.BackupTag
+			if err := rows.Scan(&t.ID, &t.Name, &t.Slug, &t.Color, &t.CreatedAt, &t.UpdatedAt, &t.DeletedAt); err != nil {
+				return err
+			}
+			archive.Tags = append(archive.Tags, t)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump tags: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `
+        SELECT id, author_id, category_id, title, slug, COALESCE(content, ''),
+               COALESCE(content_format, 'html'), COALESCE(content_html, ''), COALESCE(word_count, 0),
+               COALESCE(reading_time, 0), outline, COALESCE(excerpt, ''), COALESCE(featured_image_url, ''),
+               COALESCE(status, 'draft'), COALESCE(view_count, 0), COALESCE(is_featured, false), metadata,
+               published_at, created_at, updated_at, deleted_at
+        FROM posts ORDER BY created_at, id`,
+		func(rows *sql.Rows) error {
+			var p models.BackupPost
+			var outline, metadata []byte
+			if err := rows.Scan(&p.ID, &p.AuthorID, &p.CategoryID, &p.Title, &p.Slug, &p.Content,
+				&p.ContentFormat, &p.ContentHTML, &p.WordCount, &p.ReadingTime, &outline, &p.Excerpt,
+				&p.FeaturedImageURL, &p.Status, &p.ViewCount, &p.IsFeatured, &metadata,
+				&p.PublishedAt, &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt); err != nil {
+				return err
+			}
+			p.Outline = outline
+			p.Metadata = metadata
+			archive.Posts = append(archive.Posts, p)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump posts: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT post_id, tag_id FROM post_tags ORDER BY post_id, tag_id`,
+		func(rows *sql.Rows) error {
+			var pt models.BackupPostTag
+			if err := rows.Scan(&pt.PostID, &pt.TagID); err != nil {
+				return err
+			}
+			archive.PostTags = append(archive.PostTags, pt)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump post tags: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `
+        SELECT id, user_id, original_name, file_name, file_path, COALESCE(cloudinary_public_id, ''),
+               mime_type, file_size, metadata, created_at, updated_at, deleted_at
+        FROM media_files ORDER BY created_at, id`,
+		func(rows *sql.Rows) error {
+			var m models.BackupMediaFile
+			var metadata []byte
+			if err := rows.Scan(&m.ID, &m.UserID, &m.OriginalName, &m.FileName, &m.FilePath,
+				&m.CloudinaryPublicID, &m.MimeType, &m.FileSize, &metadata,
+				&m.CreatedAt, &m.UpdatedAt, &m.DeletedAt); err != nil {
+				return err
+			}
+			m.Metadata = metadata
+			archive.MediaFiles = append(archive.MediaFiles, m)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump media files: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `
+        SELECT id, user_id, table_name, action, old_values, new_values,
+               COALESCE(ip_address, ''), COALESCE(user_agent, ''), created_at
+        FROM audit_logs ORDER BY created_at, id`,
+		func(rows *sql.Rows) error {
+			var a models.BackupAuditLog
+			var oldValues, newValues []byte
+			if err := rows.Scan(&a.ID, &a.UserID, &a.TableName, &a.Action, &oldValues, &newValues,
+				&a.IPAddress, &a.UserAgent, &a.CreatedAt); err != nil {
+				return err
+			}
+			a.OldValues = oldValues
+			a.NewValues = newValues
+			archive.AuditLogs = append(archive.AuditLogs, a)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump audit logs: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `
+        SELECT entity_type, slug, entity_id, created_at
+        FROM slug_history ORDER BY created_at, id`,
+		func(rows *sql.Rows) error {
+			var h models.BackupSlugHistory
+			if err := rows.Scan(&h.EntityType, &h.Slug, &h.EntityID, &h.CreatedAt); err != nil {
+				return err
+			}
+			archive.SlugHistory = append(archive.SlugHistory, h)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump slug history: %w", err)
+	}
+
+	return archive, nil
+}
+
+func queryRows(ctx context.Context, tx *sql.Tx, query string, scan func(rows *sql.Rows) error) error {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Restore writes an archive into a database without content, in one
+// transaction. Every row gets a new ID and references are rewritten to match.
+// Users that already exist are matched by email and kept as they are; new
+// users without a password hash get placeholderHash.
+func (r *BackupRepository) Restore(ctx context.Context, archive *models.BackupArchive, placeholderHash string) (*models.RestoreResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var existing int
+	err = tx.QueryRowContext(ctx, `
+        SELECT (SELECT COUNT(*) FROM categories) + (SELECT COUNT(*) FROM tags) +
+               (SELECT COUNT(*) FROM posts) + (SELECT COUNT(*) FROM media_files) +
+               (SELECT COUNT(*) FROM audit_logs) + (SELECT COUNT(*) FROM slug_history)`,
+	).Scan(&existing)
+	if err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, ErrRestoreTargetNotEmpty
+	}
+
+	result := &models.RestoreResult{}
+	users := map[uuid.UUID]uuid.UUID{}
+	categories := map[uuid.UUID]uuid.UUID{}
+	tags := map[uuid.UUID]uuid.UUID{}
+	posts := map[uuid.UUID]uuid.UUID{}
+
+	for _, u := range archive.Users {
+		var id uuid.UUID
+		err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE email = $1`, u.Email).Scan(&id)
+		if err == nil {
+			users[u.ID] = id
+			result.ExistingUsers++
+			continue
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+
+		passwordHash := u.PasswordHash
+		if passwordHash == "" {
+			passwordHash = placeholderHash
+			result.UsersWithoutPassword++
+		}
+
+		id = uuid.New()
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO users (id, fullname, email, username, password_hash, role, avatar_url,
+                               is_active, email_verified_at, bio, social_links, created_at, updated_at, deleted_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+			id, u.Fullname, u.Email, u.Username, passwordHash, u.Role, u.AvatarURL,
+			u.IsActive, u.EmailVerifiedAt, u.Bio, u.SocialLinks, u.CreatedAt, u.UpdatedAt, u.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore user %s: %w", u.Email, err)
+		}
+		users[u.ID] = id
+		result.Users++
+	}
+
+	for _, c := range archive.Categories {
+		id := uuid.New()
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO categories (id, name, slug, description, created_at, updated_at, deleted_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			id, c.Name, c.Slug, c.Description, c.CreatedAt, c.UpdatedAt, c.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore category %s: %w", c.Slug, err)
+		}
+		categories[c.ID] = id
+		result.Categories++
+	}
+
+	for _, t := range archive.Tags {
+		id := uuid.New()
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO tags (id, name, slug, color, created_at, updated_at, deleted_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			id, t.Name, t.Slug, t.Color, t.CreatedAt, t.UpdatedAt, t.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore tag %s: %w", t.Slug, err)
+		}
+		tags[t.ID] = id
+		result.Tags++
+	}
+
+	for _, p := range archive.Posts {
+		authorID, ok := users[p.AuthorID]
+		if !ok {
+			return nil, fmt.Errorf("post %s author %s: %w", p.Slug, p.AuthorID, ErrBackupBrokenReference)
+		}
+		categoryID, ok := categories[p.CategoryID]
+		if !ok {
+			return nil, fmt.Errorf("post %s category %s: %w", p.Slug, p.CategoryID, ErrBackupBrokenReference)
+		}
+
+		id := uuid.New()
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO posts (id, author_id, category_id, title, slug, content, content_format,
+                               content_html, word_count, reading_time, outline, excerpt,
+                               featured_image_url, status, view_count, is_featured, metadata,
+                               published_at, created_at, updated_at, deleted_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+                    $18, $19, $20, $21)`,
+			id, authorID, categoryID, p.Title, p.Slug, p.Content, p.ContentFormat,
+			p.ContentHTML, p.WordCount, p.ReadingTime, nullableJSON(p.Outline), p.Excerpt,
+			p.FeaturedImageURL, p.Status, p.ViewCount, p.IsFeatured, nullableJSON(p.Metadata),
+			p.PublishedAt, p.CreatedAt, p.UpdatedAt, p.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore post %s: %w", p.Slug, err)
+		}
+		posts[p.ID] = id
+		result.Posts++
+	}
+
+	for _, pt := range archive.PostTags {
+		postID, ok := posts[pt.PostID]
+		if !ok {
+			return nil, fmt.Errorf("post tag post %s: %w", pt.PostID, ErrBackupBrokenReference)
+		}
+		tagID, ok := tags[pt.TagID]
+		if !ok {
+			return nil, fmt.Errorf("post tag tag %s: %w", pt.TagID, ErrBackupBrokenReference)
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2)`, postID, tagID); err != nil {
+			return nil, fmt.Errorf("failed to restore post tag: %w", err)
+		}
+		result.PostTags++
+	}
+
+	for _, m := range archive.MediaFiles {
+		userID, ok := users[m.UserID]
+		if !ok {
+			return nil, fmt.Errorf("media file %s user %s: %w", m.FileName, m.UserID, ErrBackupBrokenReference)
+		}
+
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO media_files (id, user_id, original_name, file_name, file_path,
+                                     cloudinary_public_id, mime_type, file_size, metadata,
+                                     created_at, updated_at, deleted_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			uuid.New(), userID, m.OriginalName, m.FileName, m.FilePath, m.CloudinaryPublicID,
+			m.MimeType, m.FileSize, nullableJSON(m.Metadata), m.CreatedAt, m.UpdatedAt, m.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore media file %s: %w", m.FileName, err)
+		}
+		result.MediaFiles++
+	}
+
+	for _, a := range archive.AuditLogs {
+		var userID *uuid.UUID
+		if a.UserID != nil {
+			mapped, ok := users[*a.UserID]
+			if !ok {
+				return nil, fmt.Errorf("audit log %s user %s: %w", a.ID, *a.UserID, ErrBackupBrokenReference)
+			}
+			userID = &mapped
+		}
+
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO audit_logs (id, user_id, table_name, action, old_values, new_values,
+                                    ip_address, user_agent, created_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			uuid.New(), userID, a.TableName, a.Action, nullableJSON(a.OldValues), nullableJSON(a.NewValues),
+			a.IPAddress, a.UserAgent, a.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore audit log: %w", err)
+		}
+		result.AuditLogs++
+	}
+
+	entityIDs := map[models.SlugEntityType]map[uuid.UUID]uuid.UUID{
+		models.SlugEntityPost:     posts,
+		models.SlugEntityCategory: categories,
+		models.SlugEntityTag:      tags,
+	}
+	for _, h := range archive.SlugHistory {
+		entityID, ok := entityIDs[h.EntityType][h.EntityID]
+		if !ok {
+			return nil, fmt.Errorf("slug history %s %s: %w", h.EntityType, h.Slug, ErrBackupBrokenReference)
+		}
+
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO slug_history (entity_type, slug, entity_id, created_at)
+            VALUES ($1, $2, $3, $4)`,
+			h.EntityType, h.Slug, entityID, h.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore slug history: %w", err)
+		}
+		result.SlugHistory++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// nullableJSON stores an absent JSON value as NULL rather than an empty string
+func nullableJSON(value []byte) interface{} {
+	if len(value) == 0 || string(value) == "null" {
+		return nil
+	}
+	return value
+}
@@ -4,6 +4,7 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kyomel/blog-management/internal/models"
@@ -114,10 +115,8 @@ func (r *CategoryRepository) GetBySlug(slug string) (*models.Category, error) {
 }
 
 func (r *CategoryRepository) GetAll(limit, offset int) ([]*models.Category, int, error) {
-	// Get total count
-	var total int
-	countQuery := `SELECT COUNT(*) FROM categories WHERE deleted_at IS NULL`
-	if err := r.db.QueryRow(countQuery).Scan(&total); err != nil {
+	total, err := r.Count()
+	if err != nil {
 		return nil, 0, err
 	}
 
@@ -129,10 +128,60 @@ func (r *CategoryRepository) GetAll(limit, offset int) ([]*models.Category, int,
         ORDER BY name ASC
         LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.Query(query, limit, offset)
+	categories, err := r.queryCategories(query, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
+
+	return categories, total, nil
+}
+
+func (r *CategoryRepository) Count() (int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM categories WHERE deleted_at IS NULL`
+	if err := r.db.QueryRow(countQuery).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// categoryNameKeyset orders category listings by name
+var categoryNameKeyset = textKeyset("name")
+
+// GetPage returns up to limit categories following cursor in name order
+func (r *CategoryRepository) GetPage(cursor *models.Cursor, limit int) ([]*models.Category, error) {
+	whereConditions := []string{"deleted_at IS NULL"}
+	args := []interface{}{}
+	if cursor != nil {
+		condition, cursorArgs, err := keysetCondition(categoryNameKeyset, "id", cursor, 0)
+		if err != nil {
+			return nil, err
+		}
+		whereConditions = append(whereConditions, condition)
+		args = append(args, cursorArgs...)
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+        SELECT id, name, slug, description, created_at, updated_at
+        FROM categories
+        WHERE %s
+        ORDER BY %s
+        LIMIT $%d`, strings.Join(whereConditions, " AND "), keysetOrder(categoryNameKeyset, "id", false), len(args))
+
+	return r.queryCategories(query, args...)
+}
+
+// CategoryCursor returns the cursor pointing just after category in name order
+func CategoryCursor(category *models.Category) *models.Cursor {
+	return &models.Cursor{Sort: NameSort, Key: category.Name, ID: category.ID}
+}
+
+func (r *CategoryRepository) queryCategories(query string, args ...interface{}) ([]*models.Category, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var categories []*models.Category
@@ -147,15 +196,33 @@ func (r *CategoryRepository) GetAll(limit, offset int) ([]*models.Category, int,
 			&category.UpdatedAt,
 		)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		categories = append(categories, category)
 	}
 
-	return categories, total, nil
+	return categories, rows.Err()
 }
 
 func (r *CategoryRepository) Update(category *models.Category) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldSlug string
+	err = tx.QueryRow(
+		`SELECT slug FROM categories WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		category.ID,
+	).Scan(&oldSlug)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return err
+	}
+
 	category.UpdatedAt = time.Now()
 
 	query := `
@@ -167,7 +234,7 @@ func (r *CategoryRepository) Update(category *models.Category) error {
         WHERE id = $1 AND deleted_at IS NULL
         RETURNING updated_at`
 
-	err := r.db.QueryRow(
+	err = tx.QueryRow(
 		query,
 		category.ID,
 		category.Name,
@@ -179,8 +246,15 @@ func (r *CategoryRepository) Update(category *models.Category) error {
 	if err == sql.ErrNoRows {
 		return fmt.Errorf("category not found")
 	}
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := recordSlugChange(tx, models.SlugEntityCategory, category.ID, oldSlug, category.Slug); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *CategoryRepository) Delete(id uuid.UUID) error {
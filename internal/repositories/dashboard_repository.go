@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+type DashboardRepository struct {
+	db *sql.DB
+}
+
+func NewDashboardRepository(db *sql.DB) *DashboardRepository {
+	return &DashboardRepository{db: db}
+}
+
+// GetStats computes the dashboard figures. Drafts last changed before
+// staleBefore are stale and users created after signupsSince are recent.
+// Login attempts are left out of the recent audit events, which would
+// otherwise be crowded out by them.
+func (r *DashboardRepository) GetStats(ctx context.Context, now, staleBefore, signupsSince time.Time, auditLimit int) (*models.DashboardStats, error) {
+	stats := &models.DashboardStats{PostsByStatus: map[models.PostStatus]int{}}
+
+	var drafts, published, archived int
+	err := r.db.QueryRowContext(ctx, `
+        SELECT COUNT(*) FILTER (WHERE status = 'draft'),
+               COUNT(*) FILTER (WHERE status = 'published'),
+               COUNT(*) FILTER (WHERE status = 'archived'),
+               COUNT(*) FILTER (WHERE status = 'draft' AND COALESCE(updated_at, created_at) < $1),
+               COUNT(*) FILTER (WHERE status = 'published' AND published_at > $2)
+        FROM posts
+        WHERE deleted_at IS NULL`, staleBefore, now,
+	).Scan(&drafts, &published, &archived, &stats.StaleDrafts, &stats.ScheduledPosts)
+	if err != nil {
+		return nil, err
+	}
+	stats.PostsByStatus[models.StatusDraft] = drafts
+	stats.PostsByStatus[models.StatusPublished] = published
+	stats.PostsByStatus[models.StatusArchived] = archived
+
+	err = r.db.QueryRowContext(ctx, `
+        SELECT (SELECT COUNT(*) FROM users WHERE created_at >= $1 AND deleted_at IS NULL),
+               (SELECT COUNT(*) FROM media_files WHERE deleted_at IS NULL),
+               (SELECT COALESCE(SUM(file_size), 0) FROM media_files WHERE deleted_at IS NULL)`,
+		signupsSince,
+	).Scan(&stats.RecentSignups, &stats.MediaFiles, &stats.MediaBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT a.id, a.user_id, COALESCE(u.username, ''), a.table_name, a.action, a.created_at
+        FROM audit_logs a
+        LEFT JOIN users u ON u.id = a.user_id
+        WHERE a.action NOT IN ($2, $3)
+        ORDER BY a.created_at DESC
+        LIMIT $1`, auditLimit, models.ActionLogin, models.ActionLoginFailed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats.RecentAudit = []models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		if err := rows.Scan(&entry.ID, &entry.UserID, &entry.Username, &entry.TableName, &entry.Action, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		stats.RecentAudit = append(stats.RecentAudit, entry)
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/utils"
+)
+
+// ErrInvalidCursor is utils.ErrInvalidCursor, returned for a cursor that does
+// not fit the listing's sort column
+var ErrInvalidCursor = utils.ErrInvalidCursor
+
+// Sort keys recorded in cursors of listings with a fixed order
+const (
+	NameSort     = "name"
+	TagPostsSort = "created_at"
+)
+
+// keysetColumn is a column listings can be ordered by and paged through with
+// a cursor. Rows are ordered by (expr, id) so the position is always unique.
+type keysetColumn struct {
+	expr  string
+	parse func(key string) (interface{}, error)
+}
+
+func timeKeyset(expr string) keysetColumn {
+	return keysetColumn{expr: expr, parse: func(key string) (interface{}, error) {
+		return time.Parse(time.RFC3339Nano, key)
+	}}
+}
+
+func intKeyset(expr string) keysetColumn {
+	return keysetColumn{expr: expr, parse: func(key string) (interface{}, error) {
+		return strconv.ParseInt(key, 10, 64)
+	}}
+}
+
+func textKeyset(expr string) keysetColumn {
+	return keysetColumn{expr: expr, parse: func(key string) (interface{}, error) {
+		return key, nil
+	}}
+}
+
+func timeKey(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// keysetOrder returns the ORDER BY clause for paging by column
+func keysetOrder(column keysetColumn, idExpr string, desc bool) string {
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("%s %s, %s %s", column.expr, direction, idExpr, direction)
+}
+
+// keysetCondition returns the WHERE condition selecting the rows after cursor.
+// Placeholders are numbered from argCount+1.
+func keysetCondition(column keysetColumn, idExpr string, cursor *models.Cursor, argCount int) (string, []interface{}, error) {
+	key, err := column.parse(cursor.Key)
+	if err != nil {
+		return "", nil, ErrInvalidCursor
+	}
+
+	op := ">"
+	if cursor.Desc {
+		op = "<"
+	}
+	condition := fmt.Sprintf("(%s, %s) %s ($%d, $%d)", column.expr, idExpr, op, argCount+1, argCount+2)
+	return condition, []interface{}{key, cursor.ID}, nil
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+type MediaRepository struct {
+	db *sql.DB
+}
+
+func NewMediaRepository(db *sql.DB) *MediaRepository {
+	return &MediaRepository{db: db}
+}
+
+func (r *MediaRepository) Create(media *models.MediaFile) error {
+	now := time.Now()
+	media.CreatedAt = now
+	media.UpdatedAt = now
+
+	query := `
+        INSERT INTO media_files (user_id, original_name, file_name, file_path, cloudinary_public_id,
+                                 mime_type, file_size, metadata, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING id`
+
+	return r.db.QueryRow(
+		query,
+		media.UserID,
+		media.OriginalName,
+		media.FileName,
+		media.FilePath,
+		media.CloudinaryPublicID,
+		media.MimeType,
+		media.FileSize,
+		media.Metadata,
+		media.CreatedAt,
+		media.UpdatedAt,
+	).Scan(&media.ID)
+}
+
+// FindBySourceURL returns the media file that was rehosted from sourceURL, or nil
+func (r *MediaRepository) FindBySourceURL(sourceURL string) (*models.MediaFile, error) {
+	media := &models.MediaFile{}
+	var metadata []byte
+	query := `
+        SELECT id, user_id, original_name, file_name, file_path, cloudinary_public_id,
+               mime_type, file_size, metadata, created_at, updated_at
+        FROM media_files
+        WHERE metadata->>'source_url' = $1 AND deleted_at IS NULL
+        LIMIT 1`
+
+	err := r.db.QueryRow(query, sourceURL).Scan(
+		&media.ID,
+		&media.UserID,
+		&media.OriginalName,
+		&media.FileName,
+		&media.FilePath,
+		&media.CloudinaryPublicID,
+		&media.MimeType,
+		&media.FileSize,
+		&metadata,
+		&media.CreatedAt,
+		&media.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	media.Metadata = metadata
+	return media, nil
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+var (
+	ErrOIDCStateInvalid = errors.New("login state is invalid or expired")
+	ErrIdentityNotFound = errors.New("identity not found")
+	ErrIdentityLinked   = errors.New("identity is already linked to a user")
+)
+
+type OIDCRepository struct {
+	db *sql.DB
+}
+
+func NewOIDCRepository(db *sql.DB) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+// SaveState stores a pending login and clears out expired ones
+func (r *OIDCRepository) SaveState(ctx context.Context, state *models.OIDCLoginState) error {
+	now := time.Now()
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM oidc_login_states WHERE expires_at <= $1", now); err != nil {
+		return err
+	}
+
+	state.CreatedAt = now
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO oidc_login_states (state_hash, provider, nonce, code_verifier, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		state.StateHash, state.Provider, state.Nonce, state.CodeVerifier, state.ExpiresAt, state.CreatedAt)
+	return err
+}
+
+// ConsumeState deletes and returns a live state of the provider, so each
+// authorization response can be used once
+func (r *OIDCRepository) ConsumeState(ctx context.Context, provider, stateHash string) (*models.OIDCLoginState, error) {
+	var state models.OIDCLoginState
+	err := r.db.QueryRowContext(ctx, `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1 AND provider = $2 AND expires_at > $3
+		RETURNING state_hash, provider, nonce, code_verifier, expires_at, created_at`,
+		stateHash, provider, time.Now(),
+	).Scan(&state.StateHash, &state.Provider, &state.Nonce, &state.CodeVerifier, &state.ExpiresAt, &state.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrOIDCStateInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// FindIdentity returns the link for a provider account
+func (r *OIDCRepository) FindIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, provider, subject, COALESCE(email, ''), last_login_at, created_at
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2`,
+		provider, subject,
+	).Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.LastLoginAt, &identity.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrIdentityNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateIdentity links a provider account to a user
+func (r *OIDCRepository) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	if identity.ID == uuid.Nil {
+		identity.ID = uuid.New()
+	}
+	identity.CreatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_identities (id, user_id, provider, subject, email, last_login_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (provider, subject) DO NOTHING`,
+		identity.ID, identity.UserID, identity.Provider, identity.Subject, identity.Email, identity.LastLoginAt, identity.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrIdentityLinked
+	}
+	return nil
+}
+
+// TouchIdentity records a login through the identity
+func (r *OIDCRepository) TouchIdentity(ctx context.Context, id uuid.UUID, email string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE user_identities
+		SET last_login_at = $2, email = $3
+		WHERE id = $1`,
+		id, time.Now(), email)
+	return err
+}
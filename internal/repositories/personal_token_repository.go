@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+var (
+	ErrPersonalTokenNotFound = errors.New("personal access token not found")
+)
+
+type PersonalTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPersonalTokenRepository(db *sql.DB) *PersonalTokenRepository {
+	return &PersonalTokenRepository{db: db}
+}
+
+func (r *PersonalTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	token.CreatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO personal_access_tokens (id, user_id, name, prefix, token_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		token.ID, token.UserID, token.Name, token.Prefix, token.TokenHash, token.Scopes, token.ExpiresAt, token.CreatedAt)
+	return err
+}
+
+// ListByUser returns the user's tokens, newest first, including expired ones
+func (r *PersonalTokenRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, name, prefix, token_hash, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.PersonalAccessToken{}
+	for rows.Next() {
+		token, err := scanPersonalToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *PersonalTokenRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM personal_access_tokens WHERE user_id = $1", userID).Scan(&count)
+	return count, err
+}
+
+// FindLiveByHash returns an unexpired token
+func (r *PersonalTokenRepository) FindLiveByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, name, prefix, token_hash, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1 AND (expires_at IS NULL OR expires_at > $2)`,
+		tokenHash, time.Now())
+
+	token, err := scanPersonalToken(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrPersonalTokenNotFound
+	}
+	return token, err
+}
+
+// Delete revokes one of the user's tokens
+func (r *PersonalTokenRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrPersonalTokenNotFound
+	}
+	return nil
+}
+
+// DeleteByUser revokes all of the user's tokens
+func (r *PersonalTokenRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM personal_access_tokens WHERE user_id = $1", userID)
+	return err
+}
+
+// Touch records a use of the token. It writes at most once per interval, so
+// a busy script does not cause a write for every request.
+func (r *PersonalTokenRepository) Touch(ctx context.Context, id uuid.UUID, interval time.Duration) error {
+	now := time.Now()
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE personal_access_tokens
+		SET last_used_at = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at <= $3)`,
+		id, now, now.Add(-interval))
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPersonalToken(row rowScanner) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.Prefix, &token.TokenHash,
+		&token.Scopes, &token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
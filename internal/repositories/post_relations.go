@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+// postIncludes returns the relations to load for a listing, defaulting to all
+func postIncludes(filter *models.PostFilter) models.PostIncludes {
+	if filter.Include == nil {
+		return models.PostIncludes{Author: true, Category: true, Tags: true}
+	}
+	return *filter.Include
+}
+
+// postListSelect builds the SELECT and FROM clauses of a post listing. The
+// author and category are joined only when included; queryPosts scans the
+// columns in the same order.
+func postListSelect(include models.PostIncludes) string {
+	columns := `p.id, p.author_id, p.category_id, p.title, p.slug, p.excerpt,
+               p.featured_image_url, p.status, p.view_count, p.is_featured,
+               p.word_count, p.reading_time,
+               p.metadata, p.published_at, p.created_at, p.updated_at`
+	joins := ""
+
+	if include.Author {
+		columns += `,
+               u.username, u.fullname, u.avatar_url`
+		joins += `
+        JOIN users u ON p.author_id = u.id`
+	}
+	if include.Category {
+		columns += `,
+               c.name, c.slug`
+		joins += `
+        JOIN categories c ON p.category_id = c.id`
+	}
+
+	return fmt.Sprintf(`
+        SELECT %s
+        FROM posts p%s`, columns, joins)
+}
+
+func (r *PostRepository) queryPosts(include models.PostIncludes, query string, args ...interface{}) ([]*models.Post, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post := &models.Post{}
+		var metadataJSON []byte
+		dest := []interface{}{
+			&post.ID,
+			&post.AuthorID,
+			&post.CategoryID,
+			&post.Title,
+			&post.Slug,
+			&post.Excerpt,
+			&post.FeaturedImageURL,
+			&post.Status,
+			&post.ViewCount,
+			&post.IsFeatured,
+			&post.WordCount,
+			&post.ReadingTime,
+			&metadataJSON,
+			&post.PublishedAt,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+		}
+		if include.Author {
+			post.Author = &models.User{}
+			dest = append(dest, &post.Author.Username, &post.Author.Fullname, &post.Author.AvatarURL)
+		}
+		if include.Category {
+			post.Category = &models.Category{}
+			dest = append(dest, &post.Category.Name, &post.Category.Slug)
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		if metadataJSON != nil {
+			post.Metadata = metadataJSON
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if include.Tags {
+		if err := r.loadPostTags(posts); err != nil {
+			return nil, err
+		}
+	}
+
+	return posts, nil
+}
+
+// loadPostTags fills in the tags of every post with a single query
+func (r *PostRepository) loadPostTags(posts []*models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*models.Post, len(posts))
+	ids := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+		ids = append(ids, post.ID)
+	}
+
+	query := `
+        SELECT pt.post_id, t.id, t.name, t.slug, t.color
+        FROM tags t
+        JOIN post_tags pt ON t.id = pt.tag_id
+        WHERE pt.post_id = ANY($1::uuid[])`
+
+	rows, err := r.db.Query(query, uuidStrings(ids))
+	if err != nil {
+		return fmt.Errorf("failed to load post tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID uuid.UUID
+		tag := &models.Tag{}
+		if err := rows.Scan(&postID, &tag.ID, &tag.Name, &tag.Slug, &tag.Color); err != nil {
+			return fmt.Errorf("failed to load post tags: %w", err)
+		}
+		if post, ok := byID[postID]; ok {
+			post.Tags = append(post.Tags, tag)
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+const (
+	countingPageSize    = 100
+	countingTagsPerPost = 3
+)
+
+// countingDriver is a database/sql driver that answers post listing queries
+// with generated rows and counts every query it receives
+type countingDriver struct {
+	queries atomic.Int64
+}
+
+func openCountingDB(tb testing.TB) (*sql.DB, *countingDriver) {
+	d := &countingDriver{}
+	db := sql.OpenDB(d)
+	tb.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+func (d *countingDriver) Open(string) (driver.Conn, error) {
+	return &countingConn{driver: d}, nil
+}
+
+func (d *countingDriver) Connect(context.Context) (driver.Conn, error) {
+	return d.Open("")
+}
+
+func (d *countingDriver) Driver() driver.Driver {
+	return d
+}
+
+type countingConn struct {
+	driver *countingDriver
+}
+
+func (c *countingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("unexpected prepare of %q", query)
+}
+
+func (c *countingConn) Close() error { return nil }
+
+func (c *countingConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("unexpected transaction")
+}
+
+// CheckNamedValue accepts every argument, including the []string passed
+// for uuid[] parameters
+func (c *countingConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c *countingConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.queries.Add(1)
+
+	switch {
+	case strings.Contains(query, "COUNT(*)"):
+		return &countingRows{columns: []string{"count"}, values: [][]driver.Value{{int64(countingPageSize)}}}, nil
+
+	case strings.Contains(query, "JOIN post_tags"):
+		ids, ok := args[0].Value.([]string)
+		if !ok {
+			return nil, fmt.Errorf("tag query got %T, want post IDs", args[0].Value)
+		}
+		rows := &countingRows{columns: []string{"post_id", "id", "name", "slug", "color"}}
+		for _, id := range ids {
+			for i := 0; i < countingTagsPerPost; i++ {
+				rows.values = append(rows.values, []driver.Value{id, uuid.NewString(), fmt.Sprintf("tag %d", i), fmt.Sprintf("tag-%d", i), "#000000"})
+			}
+		}
+		return rows, nil
+
+	case strings.Contains(query, "FROM posts p"):
+		return postRows(query), nil
+	}
+
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+// postRows generates a page of posts with the columns postListSelect picks
+func postRows(query string) *countingRows {
+	withAuthor := strings.Contains(query, "u.username")
+	withCategory := strings.Contains(query, "c.name")
+	now := time.Now()
+
+	rows := &countingRows{}
+	for i := 0; i < countingPageSize; i++ {
+		row := []driver.Value{
+			uuid.NewString(), uuid.NewString(), uuid.NewString(),
+			fmt.Sprintf("Post %d", i), fmt.Sprintf("post-%d", i), "excerpt",
+			"", string(models.StatusPublished), int64(i), false,
+			int64(100), int64(1),
+			nil, now, now, now,
+		}
+		if withAuthor {
+			row = append(row, "jane", "Jane Doe", "")
+		}
+		if withCategory {
+			row = append(row, "News", "news")
+		}
+		rows.values = append(rows.values, row)
+	}
+
+	rows.columns = make([]string, len(rows.values[0]))
+	for i := range rows.columns {
+		rows.columns[i] = fmt.Sprintf("column%d", i)
+	}
+	return rows
+}
+
+type countingRows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *countingRows) Columns() []string { return r.columns }
+
+func (r *countingRows) Close() error { return nil }
+
+func (r *countingRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
+
+func TestPostListingQueryCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		include *models.PostIncludes
+		// paged uses GetPage, which has no count query
+		paged       bool
+		wantQueries int64
+		wantTags    int
+	}{
+		{name: "all relations", include: nil, wantQueries: 3, wantTags: countingTagsPerPost},
+		{name: "without tags", include: &models.PostIncludes{Author: true, Category: true}, wantQueries: 2},
+		{name: "tags only", include: &models.PostIncludes{Tags: true}, wantQueries: 3, wantTags: countingTagsPerPost},
+		{name: "cursor page", include: nil, paged: true, wantQueries: 2, wantTags: countingTagsPerPost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, counter := openCountingDB(t)
+			repo := NewPostRepository(db)
+			filter := &models.PostFilter{Status: models.StatusPublished, Limit: countingPageSize, Include: tt.include}
+
+			var posts []*models.Post
+			var err error
+			if tt.paged {
+				posts, err = repo.GetPage(filter, nil, countingPageSize)
+			} else {
+				posts, _, err = repo.GetAll(filter)
+			}
+			if err != nil {
+				t.Fatalf("listing posts: %v", err)
+			}
+
+			if len(posts) != countingPageSize {
+				t.Fatalf("got %d posts, want %d", len(posts), countingPageSize)
+			}
+			if got := counter.queries.Load(); got != tt.wantQueries {
+				t.Errorf("ran %d queries for %d posts, want %d", got, countingPageSize, tt.wantQueries)
+			}
+			for _, post := range posts {
+				if len(post.Tags) != tt.wantTags {
+					t.Fatalf("post %s has %d tags, want %d", post.ID, len(post.Tags), tt.wantTags)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGetAllPosts reports the queries needed for a page of 100 posts
+// with every relation loaded; it must stay at 3 regardless of page size
+func BenchmarkGetAllPosts(b *testing.B) {
+	db, counter := openCountingDB(b)
+	repo := NewPostRepository(db)
+	filter := &models.PostFilter{Status: models.StatusPublished, Limit: countingPageSize}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := repo.GetAll(filter); err != nil {
+			b.Fatalf("GetAll: %v", err)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(counter.queries.Load())/float64(b.N), "queries/op")
+}
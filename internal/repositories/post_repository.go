@@ -3,7 +3,9 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +14,11 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	ErrBulkCategoryNotFound = errors.New("category not found")
+	ErrBulkTagNotFound      = errors.New("tag not found")
+)
+
 type PostRepository struct {
 	db *sql.DB
 }
@@ -28,9 +35,10 @@ func (r *PostRepository) Create(post *models.Post, tagIDs []uuid.UUID) error {
 	defer tx.Rollback()
 
 	query := `
-        INSERT INTO posts (author_id, category_id, title, slug, content, excerpt, 
+        INSERT INTO posts (author_id, category_id, title, slug, content, content_format,
+                           content_html, word_count, reading_time, outline, excerpt,
                            featured_image_url, status, is_featured, metadata, published_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
         RETURNING id, created_at, updated_at`
 
 	err = tx.QueryRow(
@@ -40,6 +48,11 @@ func (r *PostRepository) Create(post *models.Post, tagIDs []uuid.UUID) error {
 		post.Title,
 		post.Slug,
 		post.Content,
+		post.ContentFormat,
+		post.ContentHTML,
+		post.WordCount,
+		post.ReadingTime,
+		post.Outline,
 		post.Excerpt,
 		post.FeaturedImageURL,
 		post.Status,
@@ -72,8 +85,9 @@ func (r *PostRepository) GetByID(id uuid.UUID) (*models.Post, error) {
 	var metadataJSON []byte
 
 	query := `
-        SELECT p.id, p.author_id, p.category_id, p.title, p.slug, p.content, 
-               p.excerpt, p.featured_image_url, p.status, p.view_count, 
+        SELECT p.id, p.author_id, p.category_id, p.title, p.slug, p.content,
+               p.content_format, p.content_html, p.word_count, p.reading_time,
+               p.outline, p.excerpt, p.featured_image_url, p.status, p.view_count, 
                p.is_featured, p.metadata, p.published_at, p.created_at, 
                p.updated_at, p.deleted_at,
                u.username, u.fullname, u.avatar_url,
@@ -90,6 +104,11 @@ func (r *PostRepository) GetByID(id uuid.UUID) (*models.Post, error) {
 		&post.Title,
 		&post.Slug,
 		&post.Content,
+		&post.ContentFormat,
+		&post.ContentHTML,
+		&post.WordCount,
+		&post.ReadingTime,
+		&post.Outline,
 		&post.Excerpt,
 		&post.FeaturedImageURL,
 		&post.Status,
@@ -119,10 +138,8 @@ func (r *PostRepository) GetByID(id uuid.UUID) (*models.Post, error) {
 		return nil, err
 	}
 
-	tagsErr := error(nil)
-	post.Tags, tagsErr = r.getPostTags(post.ID)
-	if tagsErr != nil {
-		fmt.Printf("Error fetching tags for post %s: %v\n", post.ID, tagsErr)
+	if err := r.loadPostTags([]*models.Post{post}); err != nil {
+		return nil, err
 	}
 
 	return post, nil
@@ -136,8 +153,9 @@ func (r *PostRepository) GetBySlug(slug string) (*models.Post, error) {
 	var metadataJSON []byte
 
 	query := `
-        SELECT p.id, p.author_id, p.category_id, p.title, p.slug, p.content, 
-               p.excerpt, p.featured_image_url, p.status, p.view_count, 
+        SELECT p.id, p.author_id, p.category_id, p.title, p.slug, p.content,
+               p.content_format, p.content_html, p.word_count, p.reading_time,
+               p.outline, p.excerpt, p.featured_image_url, p.status, p.view_count, 
                p.is_featured, p.metadata, p.published_at, p.created_at, 
                p.updated_at, p.deleted_at,
                u.username, u.fullname, u.avatar_url,
@@ -154,6 +172,11 @@ func (r *PostRepository) GetBySlug(slug string) (*models.Post, error) {
 		&post.Title,
 		&post.Slug,
 		&post.Content,
+		&post.ContentFormat,
+		&post.ContentHTML,
+		&post.WordCount,
+		&post.ReadingTime,
+		&post.Outline,
 		&post.Excerpt,
 		&post.FeaturedImageURL,
 		&post.Status,
@@ -183,17 +206,20 @@ func (r *PostRepository) GetBySlug(slug string) (*models.Post, error) {
 		return nil, err
 	}
 
-	tagsErr := error(nil)
-	post.Tags, tagsErr = r.getPostTags(post.ID)
-	if tagsErr != nil {
-		fmt.Printf("Error fetching tags for post %s: %v\n", post.ID, tagsErr)
+	if err := r.loadPostTags([]*models.Post{post}); err != nil {
+		return nil, err
 	}
 
 	return post, nil
 }
 
-func (r *PostRepository) GetAll(filter *models.PostFilter) ([]*models.Post, int, error) {
+// buildPostFilter turns the filter into SQL conditions on posts aliased as p.
+// deleted selects soft-deleted posts instead of live ones.
+func buildPostFilter(filter *models.PostFilter, deleted bool) ([]string, []interface{}) {
 	whereConditions := []string{"p.deleted_at IS NULL"}
+	if deleted {
+		whereConditions = []string{"p.deleted_at IS NOT NULL"}
+	}
 	args := []interface{}{}
 	argCount := 0
 
@@ -227,83 +253,273 @@ func (r *PostRepository) GetAll(filter *models.PostFilter) ([]*models.Post, int,
 		args = append(args, "%"+filter.Search+"%")
 	}
 
-	whereClause := strings.Join(whereConditions, " AND ")
+	var categoryConditions []string
+	if len(filter.CategoryIDs) > 0 {
+		argCount++
+		categoryConditions = append(categoryConditions, fmt.Sprintf("p.category_id = ANY($%d::uuid[])", argCount))
+		args = append(args, uuidStrings(filter.CategoryIDs))
+	}
+	if len(filter.CategorySlugs) > 0 {
+		argCount++
+		categoryConditions = append(categoryConditions, fmt.Sprintf(
+			"p.category_id IN (SELECT id FROM categories WHERE slug = ANY($%d::text[]) AND deleted_at IS NULL)", argCount))
+		args = append(args, filter.CategorySlugs)
+	}
+	if len(categoryConditions) > 0 {
+		whereConditions = append(whereConditions, "("+strings.Join(categoryConditions, " OR ")+")")
+	}
 
-	var total int
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM posts p WHERE %s`, whereClause)
-	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+	if len(filter.TagSlugs) > 0 {
+		argCount++
+		matchingTags := fmt.Sprintf(`
+            FROM post_tags pt
+            JOIN tags t ON t.id = pt.tag_id
+            WHERE pt.post_id = p.id AND t.slug = ANY($%d::text[]) AND t.deleted_at IS NULL`, argCount)
+		args = append(args, filter.TagSlugs)
+
+		if filter.TagMatchAll {
+			argCount++
+			whereConditions = append(whereConditions, fmt.Sprintf("(SELECT COUNT(DISTINCT t.slug) %s) = $%d", matchingTags, argCount))
+			args = append(args, len(uniqueStrings(filter.TagSlugs)))
+		} else {
+			whereConditions = append(whereConditions, fmt.Sprintf("EXISTS (SELECT 1 %s)", matchingTags))
+		}
+	}
+
+	if filter.PublishedFrom != nil {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("p.published_at >= $%d", argCount))
+		args = append(args, *filter.PublishedFrom)
+	}
+
+	if filter.PublishedBefore != nil {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("p.published_at < $%d", argCount))
+		args = append(args, *filter.PublishedBefore)
+	}
+
+	if filter.HasFeaturedImage != nil {
+		if *filter.HasFeaturedImage {
+			whereConditions = append(whereConditions, "COALESCE(p.featured_image_url, '') <> ''")
+		} else {
+			whereConditions = append(whereConditions, "COALESCE(p.featured_image_url, '') = ''")
+		}
+	}
+
+	if len(filter.ExcludeIDs) > 0 {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("NOT (p.id = ANY($%d::uuid[]))", argCount))
+		args = append(args, uuidStrings(filter.ExcludeIDs))
+	}
+
+	if filter.MinReadingTime != nil {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("p.reading_time >= $%d", argCount))
+		args = append(args, *filter.MinReadingTime)
+	}
+
+	if filter.MaxReadingTime != nil {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("p.reading_time <= $%d", argCount))
+		args = append(args, *filter.MaxReadingTime)
+	}
+
+	return whereConditions, args
+}
+
+func (r *PostRepository) GetAll(filter *models.PostFilter) ([]*models.Post, int, error) {
+	include := postIncludes(filter)
+
+	total, err := r.Count(filter)
+	if err != nil {
 		return nil, 0, err
 	}
 
-	argCount++
-	args = append(args, filter.Limit)
-	argCount++
-	args = append(args, filter.Offset)
+	whereConditions, args := buildPostFilter(filter, false)
+	argCount := len(args)
 
-	query := fmt.Sprintf(`
-        SELECT p.id, p.author_id, p.category_id, p.title, p.slug, p.excerpt, 
-               p.featured_image_url, p.status, p.view_count, p.is_featured, 
-               p.metadata, p.published_at, p.created_at, p.updated_at,
-               u.username, u.fullname, u.avatar_url,
-               c.name, c.slug
-        FROM posts p
-        JOIN users u ON p.author_id = u.id
-        JOIN categories c ON p.category_id = c.id
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`%s
         WHERE %s
-        ORDER BY p.created_at DESC
-        LIMIT $%d OFFSET $%d`, whereClause, argCount-1, argCount)
+        ORDER BY %s
+        LIMIT $%d OFFSET $%d`, postListSelect(include), strings.Join(whereConditions, " AND "), postOrderClause(filter), argCount+1, argCount+2)
 
-	rows, err := r.db.Query(query, args...)
+	posts, err := r.queryPosts(include, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
+
+	return posts, total, nil
+}
+
+// Count returns the number of live posts matching filter
+func (r *PostRepository) Count(filter *models.PostFilter) (int, error) {
+	whereConditions, args := buildPostFilter(filter, false)
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM posts p WHERE %s`, strings.Join(whereConditions, " AND "))
+	if err := r.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetArchiveMonths counts published posts per month of publication, newest
+// first. Months are calendar months in the given IANA timezone.
+func (r *PostRepository) GetArchiveMonths(timezone string) ([]models.ArchiveMonth, error) {
+	query := `
+        SELECT EXTRACT(YEAR FROM published_at AT TIME ZONE $1)::int AS year,
+               EXTRACT(MONTH FROM published_at AT TIME ZONE $1)::int AS month,
+               COUNT(*)
+        FROM posts
+        WHERE status = $2 AND published_at IS NOT NULL AND deleted_at IS NULL
+        GROUP BY year, month
+        ORDER BY year DESC, month DESC
+    `
+
+	rows, err := r.db.Query(query, timezone, models.StatusPublished)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	var posts []*models.Post
+	months := []models.ArchiveMonth{}
 	for rows.Next() {
-		post := &models.Post{
-			Author:   &models.User{},
-			Category: &models.Category{},
-		}
-		var metadataJSON []byte
-		err := rows.Scan(
-			&post.ID,
-			&post.AuthorID,
-			&post.CategoryID,
-			&post.Title,
-			&post.Slug,
-			&post.Excerpt,
-			&post.FeaturedImageURL,
-			&post.Status,
-			&post.ViewCount,
-			&post.IsFeatured,
-			&metadataJSON,
-			&post.PublishedAt,
-			&post.CreatedAt,
-			&post.UpdatedAt,
-			&post.Author.Username,
-			&post.Author.Fullname,
-			&post.Author.AvatarURL,
-			&post.Category.Name,
-			&post.Category.Slug,
-		)
-
-		if metadataJSON != nil {
-			post.Metadata = metadataJSON
+		var month models.ArchiveMonth
+		if err := rows.Scan(&month.Year, &month.Month, &month.Count); err != nil {
+			return nil, err
 		}
+		months = append(months, month)
+	}
+	return months, rows.Err()
+}
+
+// GetPage returns up to limit posts following cursor, ordered by the filter's
+// sort key and then ID. A nil cursor starts at the first post.
+func (r *PostRepository) GetPage(filter *models.PostFilter, cursor *models.Cursor, limit int) ([]*models.Post, error) {
+	include := postIncludes(filter)
+	column := postSortColumns[postSortKey(filter)]
+
+	whereConditions, args := buildPostFilter(filter, false)
+	if cursor != nil {
+		condition, cursorArgs, err := keysetCondition(column.keysetColumn, "p.id", cursor, len(args))
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
+		whereConditions = append(whereConditions, condition)
+		args = append(args, cursorArgs...)
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`%s
+        WHERE %s
+        ORDER BY %s
+        LIMIT $%d`, postListSelect(include), strings.Join(whereConditions, " AND "), keysetOrder(column.keysetColumn, "p.id", filter.SortDesc), len(args))
+
+	return r.queryPosts(include, query, args...)
+}
+
+// PostCursor returns the cursor pointing just after post in listings ordered as filter
+func PostCursor(filter *models.PostFilter, post *models.Post) *models.Cursor {
+	sort := postSortKey(filter)
+	return &models.Cursor{
+		Sort: sort,
+		Desc: filter.SortDesc,
+		Key:  postSortColumns[sort].key(post),
+		ID:   post.ID,
+	}
+}
+
+type postSortColumn struct {
+	keysetColumn
+	// key returns the post's value of the column for use in a cursor
+	key func(post *models.Post) string
+}
+
+// postSortColumns maps the sort keys accepted in PostFilter.SortBy to columns.
+// Nullable timestamps fall back to created_at so every row has a sort key.
+var postSortColumns = map[string]postSortColumn{
+	"created_at": {
+		keysetColumn: timeKeyset("p.created_at"),
+		key:          func(p *models.Post) string { return timeKey(p.CreatedAt) },
+	},
+	"published_at": {
+		keysetColumn: timeKeyset("COALESCE(p.published_at, p.created_at)"),
+		key:          func(p *models.Post) string { return timeKey(firstTime(p.PublishedAt, p.CreatedAt)) },
+	},
+	"updated_at": {
+		keysetColumn: timeKeyset("COALESCE(p.updated_at, p.created_at)"),
+		key:          func(p *models.Post) string { return timeKey(firstTime(p.UpdatedAt, p.CreatedAt)) },
+	},
+	"view_count": {
+		keysetColumn: intKeyset("p.view_count"),
+		key:          func(p *models.Post) string { return strconv.Itoa(p.ViewCount) },
+	},
+	"title": {
+		keysetColumn: textKeyset("p.title"),
+		key:          func(p *models.Post) string { return p.Title },
+	},
+	"reading_time": {
+		keysetColumn: intKeyset("p.reading_time"),
+		key:          func(p *models.Post) string { return strconv.Itoa(p.ReadingTime) },
+	},
+}
 
-		var tagsErr error
-		post.Tags, tagsErr = r.getPostTags(post.ID)
-		if tagsErr != nil {
-			fmt.Printf("Error fetching tags for post %s: %v\n", post.ID, tagsErr)
+func firstTime(times ...*time.Time) *time.Time {
+	for _, t := range times {
+		if t != nil {
+			return t
 		}
-		posts = append(posts, post)
 	}
+	return nil
+}
 
-	return posts, total, nil
+// uuidStrings converts IDs for use as a uuid[] parameter
+func uuidStrings(ids []uuid.UUID) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return values
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// IsValidPostSort reports whether the given key can be used as PostFilter.SortBy
+func IsValidPostSort(key string) bool {
+	_, ok := postSortColumns[key]
+	return ok
+}
+
+// postSortKey returns the filter's sort key, defaulting to created_at
+func postSortKey(filter *models.PostFilter) string {
+	if _, ok := postSortColumns[filter.SortBy]; ok {
+		return filter.SortBy
+	}
+	return "created_at"
+}
+
+func postOrderClause(filter *models.PostFilter) string {
+	column, ok := postSortColumns[filter.SortBy]
+	if !ok {
+		return "p.created_at DESC"
+	}
+
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("%s %s, p.created_at DESC", column.expr, direction)
 }
 
 func (r *PostRepository) Update(post *models.Post, tagIDs []uuid.UUID) error {
@@ -313,14 +529,27 @@ func (r *PostRepository) Update(post *models.Post, tagIDs []uuid.UUID) error {
 	}
 	defer tx.Rollback()
 
+	var oldSlug string
+	err = tx.QueryRow(
+		`SELECT slug FROM posts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		post.ID,
+	).Scan(&oldSlug)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("post not found")
+	}
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	post.UpdatedAt = &now
 
 	query := `
         UPDATE posts
-        SET category_id = $2, title = $3, slug = $4, content = $5, excerpt = $6,
-            featured_image_url = $7, status = $8, is_featured = $9, metadata = $10,
-            updated_at = $11
+        SET category_id = $2, title = $3, slug = $4, content = $5, content_format = $6,
+            content_html = $7, word_count = $8, reading_time = $9, outline = $10,
+            excerpt = $11, featured_image_url = $12, status = $13, is_featured = $14,
+            metadata = $15, published_at = $16, updated_at = $17
         WHERE id = $1 AND deleted_at IS NULL
         RETURNING updated_at`
 
@@ -331,11 +560,17 @@ func (r *PostRepository) Update(post *models.Post, tagIDs []uuid.UUID) error {
 		post.Title,
 		post.Slug,
 		post.Content,
+		post.ContentFormat,
+		post.ContentHTML,
+		post.WordCount,
+		post.ReadingTime,
+		post.Outline,
 		post.Excerpt,
 		post.FeaturedImageURL,
 		post.Status,
 		post.IsFeatured,
 		post.Metadata,
+		post.PublishedAt,
 		post.UpdatedAt,
 	).Scan(&post.UpdatedAt)
 
@@ -347,6 +582,10 @@ func (r *PostRepository) Update(post *models.Post, tagIDs []uuid.UUID) error {
 		return err
 	}
 
+	if err := recordSlugChange(tx, models.SlugEntityPost, post.ID, oldSlug, post.Slug); err != nil {
+		return err
+	}
+
 	if _, err := tx.Exec(`DELETE FROM post_tags WHERE post_id = $1`, post.ID); err != nil {
 		return err
 	}
@@ -386,12 +625,6 @@ func (r *PostRepository) Delete(id uuid.UUID) error {
 	return nil
 }
 
-func (r *PostRepository) IncrementViewCount(id uuid.UUID) error {
-	query := `UPDATE posts SET view_count = view_count + 1 WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
-}
-
 func (r *PostRepository) Publish(id uuid.UUID) error {
 	query := `
         UPDATE posts
@@ -402,28 +635,181 @@ func (r *PostRepository) Publish(id uuid.UUID) error {
 	return err
 }
 
-func (r *PostRepository) getPostTags(postID uuid.UUID) ([]*models.Tag, error) {
-	query := `
-        SELECT t.id, t.name, t.slug, t.color
-        FROM tags t
-        JOIN post_tags pt ON t.id = pt.tag_id
-        WHERE pt.post_id = $1`
+// FindIDs returns up to limit IDs of posts matching the filter, newest first.
+// deleted selects soft-deleted posts instead of live ones.
+func (r *PostRepository) FindIDs(filter *models.PostFilter, deleted bool, limit int) ([]uuid.UUID, error) {
+	whereConditions, args := buildPostFilter(filter, deleted)
+	args = append(args, limit)
 
-	rows, err := r.db.Query(query, postID)
+	query := fmt.Sprintf(`
+        SELECT p.id
+        FROM posts p
+        WHERE %s
+        ORDER BY p.created_at DESC
+        LIMIT $%d`, strings.Join(whereConditions, " AND "), len(args))
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tags []*models.Tag
+	var ids []uuid.UUID
 	for rows.Next() {
-		tag := &models.Tag{}
-		err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.Color)
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// BulkApply runs the action against every post in one transaction. Each post
+// runs under its own savepoint so a failing post does not abort the others.
+// With req.DryRun the transaction is rolled back once the results are known.
+func (r *PostRepository) BulkApply(ids []uuid.UUID, req *models.BulkPostRequest) ([]models.BulkPostItemResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if req.Action == models.BulkActionSetCategory {
+		var exists bool
+		err := tx.QueryRow(
+			`SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND deleted_at IS NULL)`,
+			req.CategoryID,
+		).Scan(&exists)
 		if err != nil {
 			return nil, err
 		}
-		tags = append(tags, tag)
+		if !exists {
+			return nil, ErrBulkCategoryNotFound
+		}
+	}
+
+	if req.Action == models.BulkActionAddTags {
+		for _, tagID := range req.TagIDs {
+			var exists bool
+			err := tx.QueryRow(
+				`SELECT EXISTS(SELECT 1 FROM tags WHERE id = $1 AND deleted_at IS NULL)`,
+				tagID,
+			).Scan(&exists)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				return nil, ErrBulkTagNotFound
+			}
+		}
+	}
+
+	now := time.Now()
+	results := make([]models.BulkPostItemResult, 0, len(ids))
+	for _, id := range ids {
+		if _, err := tx.Exec(`SAVEPOINT bulk_post`); err != nil {
+			return nil, err
+		}
+
+		result := models.BulkPostItemResult{ID: id, Success: true}
+		if err := applyBulkAction(tx, id, req, now); err != nil {
+			if _, rbErr := tx.Exec(`ROLLBACK TO SAVEPOINT bulk_post`); rbErr != nil {
+				return nil, rbErr
+			}
+			result.Success = false
+			result.Error = err.Error()
+		} else if _, err := tx.Exec(`RELEASE SAVEPOINT bulk_post`); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if req.DryRun {
+		return results, nil
 	}
 
-	return tags, nil
+	return results, tx.Commit()
+}
+
+func applyBulkAction(tx *sql.Tx, id uuid.UUID, req *models.BulkPostRequest, now time.Time) error {
+	var deleted bool
+	var slug string
+	err := tx.QueryRow(
+		`SELECT deleted_at IS NOT NULL, slug FROM posts WHERE id = $1 FOR UPDATE`,
+		id,
+	).Scan(&deleted, &slug)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("post not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if req.Action == models.BulkActionRestore {
+		if !deleted {
+			return fmt.Errorf("post is not deleted")
+		}
+
+		var conflict bool
+		err := tx.QueryRow(
+			`SELECT EXISTS(SELECT 1 FROM posts WHERE slug = $1 AND deleted_at IS NULL)`,
+			slug,
+		).Scan(&conflict)
+		if err != nil {
+			return err
+		}
+		if conflict {
+			return fmt.Errorf("slug is used by another post")
+		}
+
+		_, err = tx.Exec(`UPDATE posts SET deleted_at = NULL, updated_at = $2 WHERE id = $1`, id, now)
+		return err
+	}
+
+	if deleted {
+		return fmt.Errorf("post not found")
+	}
+
+	switch req.Action {
+	case models.BulkActionPublish:
+		_, err = tx.Exec(`
+            UPDATE posts
+            SET status = 'published', published_at = COALESCE(published_at, $2), updated_at = $2
+            WHERE id = $1`, id, now)
+	case models.BulkActionArchive:
+		_, err = tx.Exec(`UPDATE posts SET status = 'archived', updated_at = $2 WHERE id = $1`, id, now)
+	case models.BulkActionDelete:
+		_, err = tx.Exec(`UPDATE posts SET deleted_at = $2 WHERE id = $1`, id, now)
+	case models.BulkActionSetCategory:
+		_, err = tx.Exec(`UPDATE posts SET category_id = $2, updated_at = $3 WHERE id = $1`, id, req.CategoryID, now)
+	case models.BulkActionAddTags:
+		for _, tagID := range req.TagIDs {
+			_, err = tx.Exec(`
+                INSERT INTO post_tags (post_id, tag_id)
+                VALUES ($1, $2)
+                ON CONFLICT DO NOTHING`, id, tagID)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(`UPDATE posts SET updated_at = $2 WHERE id = $1`, id, now)
+	case models.BulkActionRemoveTags:
+		for _, tagID := range req.TagIDs {
+			_, err = tx.Exec(`DELETE FROM post_tags WHERE post_id = $1 AND tag_id = $2`, id, tagID)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(`UPDATE posts SET updated_at = $2 WHERE id = $1`, id, now)
+	case models.BulkActionFeature:
+		_, err = tx.Exec(`UPDATE posts SET is_featured = true, updated_at = $2 WHERE id = $1`, id, now)
+	case models.BulkActionUnfeature:
+		_, err = tx.Exec(`UPDATE posts SET is_featured = false, updated_at = $2 WHERE id = $1`, id, now)
+	default:
+		return fmt.Errorf("unsupported action %q", req.Action)
+	}
+
+	return err
 }
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+type SlugHistoryRepository struct {
+	db *sql.DB
+}
+
+func NewSlugHistoryRepository(db *sql.DB) *SlugHistoryRepository {
+	return &SlugHistoryRepository{db: db}
+}
+
+// FindEntityID returns the ID of the entity that previously used the slug, or nil if none did
+func (r *SlugHistoryRepository) FindEntityID(entityType models.SlugEntityType, slug string) (*uuid.UUID, error) {
+	var entityID uuid.UUID
+	query := `
+        SELECT entity_id
+        FROM slug_history
+        WHERE entity_type = $1 AND slug = $2`
+
+	err := r.db.QueryRow(query, entityType, slug).Scan(&entityID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &entityID, nil
+}
+
+// recordSlugChange remembers oldSlug as a redirect to the entity. An existing
+// entry for newSlug is dropped because the live slug takes precedence.
+func recordSlugChange(tx *sql.Tx, entityType models.SlugEntityType, entityID uuid.UUID, oldSlug, newSlug string) error {
+	if oldSlug == newSlug {
+		return nil
+	}
+
+	if _, err := tx.Exec(
+		`DELETE FROM slug_history WHERE entity_type = $1 AND slug = $2`,
+		entityType, newSlug,
+	); err != nil {
+		return err
+	}
+
+	query := `
+        INSERT INTO slug_history (entity_type, slug, entity_id, created_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (entity_type, slug)
+        DO UPDATE SET entity_id = EXCLUDED.entity_id, created_at = EXCLUDED.created_at`
+
+	_, err := tx.Exec(query, entityType, oldSlug, entityID, time.Now())
+	return err
+}
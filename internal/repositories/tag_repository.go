@@ -3,6 +3,7 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -112,10 +113,8 @@ func (r *TagRepository) GetBySlug(slug string) (*models.Tag, error) {
 }
 
 func (r *TagRepository) GetAll(limit, offset int) ([]*models.Tag, int, error) {
-	// Get total count
-	var total int
-	countQuery := `SELECT COUNT(*) FROM tags WHERE deleted_at IS NULL`
-	if err := r.db.QueryRow(countQuery).Scan(&total); err != nil {
+	total, err := r.Count()
+	if err != nil {
 		return nil, 0, err
 	}
 
@@ -127,10 +126,60 @@ func (r *TagRepository) GetAll(limit, offset int) ([]*models.Tag, int, error) {
         ORDER BY name ASC
         LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.Query(query, limit, offset)
+	tags, err := r.queryTags(query, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
+
+	return tags, total, nil
+}
+
+func (r *TagRepository) Count() (int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM tags WHERE deleted_at IS NULL`
+	if err := r.db.QueryRow(countQuery).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// tagNameKeyset orders tag listings by name
+var tagNameKeyset = textKeyset("name")
+
+// GetPage returns up to limit tags following cursor in name order
+func (r *TagRepository) GetPage(cursor *models.Cursor, limit int) ([]*models.Tag, error) {
+	whereConditions := []string{"deleted_at IS NULL"}
+	args := []interface{}{}
+	if cursor != nil {
+		condition, cursorArgs, err := keysetCondition(tagNameKeyset, "id", cursor, 0)
+		if err != nil {
+			return nil, err
+		}
+		whereConditions = append(whereConditions, condition)
+		args = append(args, cursorArgs...)
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+        SELECT id, name, slug, color, created_at, updated_at
+        FROM tags
+        WHERE %s
+        ORDER BY %s
+        LIMIT $%d`, strings.Join(whereConditions, " AND "), keysetOrder(tagNameKeyset, "id", false), len(args))
+
+	return r.queryTags(query, args...)
+}
+
+// TagCursor returns the cursor pointing just after tag in name order
+func TagCursor(tag *models.Tag) *models.Cursor {
+	return &models.Cursor{Sort: NameSort, Key: tag.Name, ID: tag.ID}
+}
+
+func (r *TagRepository) queryTags(query string, args ...interface{}) ([]*models.Tag, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var tags []*models.Tag
@@ -145,15 +194,33 @@ func (r *TagRepository) GetAll(limit, offset int) ([]*models.Tag, int, error) {
 			&tag.UpdatedAt,
 		)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		tags = append(tags, tag)
 	}
 
-	return tags, total, nil
+	return tags, rows.Err()
 }
 
 func (r *TagRepository) Update(tag *models.Tag) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldSlug string
+	err = tx.QueryRow(
+		`SELECT slug FROM tags WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		tag.ID,
+	).Scan(&oldSlug)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("tag not found")
+	}
+	if err != nil {
+		return err
+	}
+
 	tag.UpdatedAt = time.Now()
 
 	query := `
@@ -165,7 +232,7 @@ func (r *TagRepository) Update(tag *models.Tag) error {
         WHERE id = $1 AND deleted_at IS NULL
         RETURNING updated_at`
 
-	err := r.db.QueryRow(
+	err = tx.QueryRow(
 		query,
 		tag.ID,
 		tag.Name,
@@ -177,8 +244,15 @@ func (r *TagRepository) Update(tag *models.Tag) error {
 	if err == sql.ErrNoRows {
 		return fmt.Errorf("tag not found")
 	}
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := recordSlugChange(tx, models.SlugEntityTag, tag.ID, oldSlug, tag.Slug); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *TagRepository) Delete(id uuid.UUID) error {
@@ -274,7 +348,26 @@ func (r *TagRepository) AddTagsToPost(postID uuid.UUID, tagIDs []uuid.UUID) erro
 
 // GetPostsByTagID retrieves all posts associated with a specific tag
 func (r *TagRepository) GetPostsByTagID(tagID uuid.UUID, limit, offset int) ([]*models.Post, int, error) {
-	// Get total count
+	total, err := r.CountPostsByTagID(tagID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get posts
+	query := tagPostsSelect + `
+        WHERE pt.tag_id = $1 AND p.deleted_at IS NULL
+        ORDER BY p.created_at DESC
+        LIMIT $2 OFFSET $3`
+
+	posts, err := r.queryTagPosts(query, tagID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return posts, total, nil
+}
+
+func (r *TagRepository) CountPostsByTagID(tagID uuid.UUID) (int, error) {
 	var total int
 	countQuery := `
         SELECT COUNT(p.id)
@@ -283,23 +376,52 @@ func (r *TagRepository) GetPostsByTagID(tagID uuid.UUID, limit, offset int) ([]*
         WHERE pt.tag_id = $1 AND p.deleted_at IS NULL`
 
 	if err := r.db.QueryRow(countQuery, tagID).Scan(&total); err != nil {
-		return nil, 0, err
+		return 0, err
 	}
+	return total, nil
+}
 
-	// Get posts
-	query := `
+// tagPostsKeyset orders a tag's posts newest first
+var tagPostsKeyset = timeKeyset("p.created_at")
+
+// GetPostsPageByTagID returns up to limit posts of a tag following cursor, newest first
+func (r *TagRepository) GetPostsPageByTagID(tagID uuid.UUID, cursor *models.Cursor, limit int) ([]*models.Post, error) {
+	whereConditions := []string{"pt.tag_id = $1", "p.deleted_at IS NULL"}
+	args := []interface{}{tagID}
+	if cursor != nil {
+		condition, cursorArgs, err := keysetCondition(tagPostsKeyset, "p.id", cursor, len(args))
+		if err != nil {
+			return nil, err
+		}
+		whereConditions = append(whereConditions, condition)
+		args = append(args, cursorArgs...)
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`%s
+        WHERE %s
+        ORDER BY %s
+        LIMIT $%d`, tagPostsSelect, strings.Join(whereConditions, " AND "), keysetOrder(tagPostsKeyset, "p.id", true), len(args))
+
+	return r.queryTagPosts(query, args...)
+}
+
+// TagPostCursor returns the cursor pointing just after post in a tag's post listing
+func TagPostCursor(post *models.Post) *models.Cursor {
+	return &models.Cursor{Sort: TagPostsSort, Desc: true, Key: timeKey(post.CreatedAt), ID: post.ID}
+}
+
+const tagPostsSelect = `
         SELECT p.id, p.author_id, p.category_id, p.title, p.slug, p.excerpt, 
                p.featured_image_url, p.status, p.view_count, p.is_featured, 
                p.published_at, p.created_at, p.updated_at
         FROM posts p
-        JOIN post_tags pt ON p.id = pt.post_id
-        WHERE pt.tag_id = $1 AND p.deleted_at IS NULL
-        ORDER BY p.created_at DESC
-        LIMIT $2 OFFSET $3`
+        JOIN post_tags pt ON p.id = pt.post_id`
 
-	rows, err := r.db.Query(query, tagID, limit, offset)
+func (r *TagRepository) queryTagPosts(query string, args ...interface{}) ([]*models.Post, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -322,10 +444,10 @@ func (r *TagRepository) GetPostsByTagID(tagID uuid.UUID, limit, offset int) ([]*
 			&post.UpdatedAt,
 		)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		posts = append(posts, post)
 	}
 
-	return posts, total, nil
+	return posts, rows.Err()
 }
@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+var (
+	ErrTrashItemNotFound = errors.New("trash item not found")
+	ErrTrashSlugConflict = errors.New("slug is used by another item")
+	ErrTrashItemInUse    = errors.New("trash item is still referenced")
+	ErrInvalidTrashType  = errors.New("invalid trash type")
+)
+
+type trashTable struct {
+	table      string
+	nameColumn string
+	slugColumn string
+	slugEntity models.SlugEntityType
+	// references are table/column pairs that block purging while they point at the row
+	references [][2]string
+	// dependents are table/column pairs whose rows are removed along with the row
+	dependents [][2]string
+	// detached are table/column pairs set to NULL when the row is purged, for
+	// history that must outlive it
+	detached [][2]string
+}
+
+var trashTables = map[models.TrashEntityType]trashTable{
+	models.TrashPosts: {
+		table:      "posts",
+		nameColumn: "title",
+		slugColumn: "slug",
+		slugEntity: models.SlugEntityPost,
+		dependents: [][2]string{{"post_tags", "post_id"}, {"post_view_daily", "post_id"}},
+	},
+	models.TrashCategories: {
+		table:      "categories",
+		nameColumn: "name",
+		slugColumn: "slug",
+		slugEntity: models.SlugEntityCategory,
+		references: [][2]string{{"posts", "category_id"}},
+	},
+	models.TrashTags: {
+		table:      "tags",
+		nameColumn: "name",
+		slugColumn: "slug",
+		slugEntity: models.SlugEntityTag,
+		dependents: [][2]string{{"post_tags", "tag_id"}},
+	},
+	models.TrashUsers: {
+		table:      "users",
+		nameColumn: "username",
+		references: [][2]string{{"posts", "author_id"}, {"media_files", "user_id"}},
+		dependents: [][2]string{{"user_tokens", "user_id"}, {"user_totp", "user_id"}, {"user_recovery_codes", "user_id"}, {"user_identities", "user_id"}, {"personal_access_tokens", "user_id"}},
+		detached:   [][2]string{{"audit_logs", "user_id"}},
+	},
+}
+
+// purgeOrder purges posts first so the categories, tags and users they
+// reference can be purged in the same run
+var purgeOrder = []models.TrashEntityType{
+	models.TrashPosts,
+	models.TrashTags,
+	models.TrashCategories,
+	models.TrashUsers,
+}
+
+type TrashRepository struct {
+	db *sql.DB
+}
+
+func NewTrashRepository(db *sql.DB) *TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+func lookupTrashTable(entityType models.TrashEntityType) (trashTable, error) {
+	t, ok := trashTables[entityType]
+	if !ok {
+		return trashTable{}, ErrInvalidTrashType
+	}
+	return t, nil
+}
+
+// GetAll lists soft-deleted rows of the given type, most recently deleted first
+func (r *TrashRepository) GetAll(entityType models.TrashEntityType, limit, offset int) ([]*models.TrashItem, int, error) {
+	t, err := lookupTrashTable(entityType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE deleted_at IS NOT NULL`, t.table)
+	if err := r.db.QueryRow(countQuery).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	slugExpr := "''"
+	if t.slugColumn != "" {
+		slugExpr = t.slugColumn
+	}
+
+	query := fmt.Sprintf(`
+        SELECT id, %s, %s, deleted_at
+        FROM %s
+        WHERE deleted_at IS NOT NULL
+        ORDER BY deleted_at DESC
+        LIMIT $1 OFFSET $2`, t.nameColumn, slugExpr, t.table)
+
+	rows, err := r.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var items []*models.TrashItem
+	for rows.Next() {
+		item := &models.TrashItem{Type: entityType}
+		if err := rows.Scan(&item.ID, &item.Name, &item.Slug, &item.DeletedAt); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+
+	return items, total, rows.Err()
+}
+
+// Restore clears deleted_at on the row, refusing if a live row took its slug meanwhile
+func (r *TrashRepository) Restore(entityType models.TrashEntityType, id uuid.UUID) error {
+	t, err := lookupTrashTable(entityType)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	existsQuery := fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE id = $1 AND deleted_at IS NOT NULL)`, t.table)
+	if err := tx.QueryRow(existsQuery, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrTrashItemNotFound
+	}
+
+	if t.slugColumn != "" {
+		var conflict bool
+		conflictQuery := fmt.Sprintf(`
+            SELECT EXISTS(
+                SELECT 1 FROM %[1]s live
+                JOIN %[1]s deleted ON deleted.%[2]s = live.%[2]s
+                WHERE deleted.id = $1 AND live.deleted_at IS NULL
+            )`, t.table, t.slugColumn)
+		if err := tx.QueryRow(conflictQuery, id).Scan(&conflict); err != nil {
+			return err
+		}
+		if conflict {
+			return ErrTrashSlugConflict
+		}
+	}
+
+	restoreQuery := fmt.Sprintf(`UPDATE %s SET deleted_at = NULL, updated_at = $2 WHERE id = $1`, t.table)
+	if _, err := tx.Exec(restoreQuery, id, time.Now()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Purge permanently deletes a soft-deleted row together with its dependent
+// rows. Audit entries are kept but no longer point at a purged user.
+func (r *TrashRepository) Purge(entityType models.TrashEntityType, id uuid.UUID) error {
+	t, err := lookupTrashTable(entityType)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	existsQuery := fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE id = $1 AND deleted_at IS NOT NULL)`, t.table)
+	if err := tx.QueryRow(existsQuery, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrTrashItemNotFound
+	}
+
+	for _, ref := range t.references {
+		var referenced bool
+		refQuery := fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE %s = $1)`, ref[0], ref[1])
+		if err := tx.QueryRow(refQuery, id).Scan(&referenced); err != nil {
+			return err
+		}
+		if referenced {
+			return ErrTrashItemInUse
+		}
+	}
+
+	for _, dep := range t.dependents {
+		depQuery := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1`, dep[0], dep[1])
+		if _, err := tx.Exec(depQuery, id); err != nil {
+			return err
+		}
+	}
+
+	for _, ref := range t.detached {
+		detachQuery := fmt.Sprintf(`UPDATE %s SET %s = NULL WHERE %s = $1`, ref[0], ref[1], ref[1])
+		if _, err := tx.Exec(detachQuery, id); err != nil {
+			return err
+		}
+	}
+
+	if t.slugEntity != "" {
+		if _, err := tx.Exec(
+			`DELETE FROM slug_history WHERE entity_type = $1 AND entity_id = $2`,
+			t.slugEntity, id,
+		); err != nil {
+			return err
+		}
+	}
+
+	deleteQuery := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, t.table)
+	if _, err := tx.Exec(deleteQuery, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeDeletedBefore purges every row soft-deleted before cutoff. Rows that are
+// still referenced are skipped. It returns the number of rows purged.
+func (r *TrashRepository) PurgeDeletedBefore(cutoff time.Time) (int, error) {
+	purged := 0
+	for _, entityType := range purgeOrder {
+		t := trashTables[entityType]
+
+		query := fmt.Sprintf(`SELECT id FROM %s WHERE deleted_at IS NOT NULL AND deleted_at < $1`, t.table)
+		rows, err := r.db.Query(query, cutoff)
+		if err != nil {
+			return purged, err
+		}
+
+		var ids []uuid.UUID
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return purged, err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return purged, err
+		}
+
+		for _, id := range ids {
+			err := r.Purge(entityType, id)
+			if errors.Is(err, ErrTrashItemInUse) || errors.Is(err, ErrTrashItemNotFound) {
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+			purged++
+		}
+	}
+
+	return purged, nil
+}
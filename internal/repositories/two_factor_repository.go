@@ -0,0 +1,222 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+var (
+	ErrTOTPNotFound       = errors.New("no TOTP secret for user")
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+)
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// GetTOTP returns the user's TOTP secret, pending or confirmed
+func (r *TwoFactorRepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*models.UserTOTP, error) {
+	var totp models.UserTOTP
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, secret, last_used_step, confirmed_at, created_at, updated_at
+		FROM user_totp
+		WHERE user_id = $1`,
+		userID,
+	).Scan(&totp.UserID, &totp.Secret, &totp.LastUsedStep, &totp.ConfirmedAt, &totp.CreatedAt, &totp.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrTOTPNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// SavePendingTOTP stores a new unconfirmed secret, replacing an earlier
+// pending one. A confirmed secret is never overwritten.
+func (r *TwoFactorRepository) SavePendingTOTP(ctx context.Context, userID uuid.UUID, secret string) error {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_totp (user_id, secret, last_used_step, created_at, updated_at)
+		VALUES ($1, $2, 0, $3, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, updated_at = EXCLUDED.updated_at
+		WHERE user_totp.confirmed_at IS NULL`,
+		userID, secret, now)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTOTPAlreadyEnabled
+	}
+	return nil
+}
+
+// UseTOTPStep records step as used and reports false if it, or a later step,
+// was used before. The check and update are one statement so a code can be
+// used only once even by concurrent requests.
+func (r *TwoFactorRepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	return useTOTPStep(ctx, r.db, userID, step)
+}
+
+func useTOTPStep(ctx context.Context, db execer, userID uuid.UUID, step int64) (bool, error) {
+	result, err := db.ExecContext(ctx, `
+		UPDATE user_totp
+		SET last_used_step = $2, updated_at = $3
+		WHERE user_id = $1 AND last_used_step < $2`,
+		userID, step, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// EnableTOTP confirms the pending secret with the step of the first valid
+// code, stores the recovery code hashes and bumps the user's token version so
+// sessions started with only a password are revoked
+func (r *TwoFactorRepository) EnableTOTP(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	used, err := useTOTPStep(ctx, tx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrTOTPNotFound
+	}
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_totp
+		SET confirmed_at = $2
+		WHERE user_id = $1 AND confirmed_at IS NULL`,
+		userID, now)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rows == 0 {
+		return ErrTOTPAlreadyEnabled
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes, now); err != nil {
+		return err
+	}
+
+	result, err = tx.ExecContext(ctx, `
+		UPDATE users
+		SET two_factor_enabled_at = $2, token_version = token_version + 1, updated_at = $2
+		WHERE id = $1 AND deleted_at IS NULL`,
+		userID, now)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	return tx.Commit()
+}
+
+// DisableTOTP removes the secret and recovery codes
+func (r *TwoFactorRepository) DisableTOTP(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		"DELETE FROM user_totp WHERE user_id = $1",
+		"DELETE FROM user_recovery_codes WHERE user_id = $1",
+		"UPDATE users SET two_factor_enabled_at = NULL WHERE id = $1",
+	} {
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes, used or not, and
+// stores new ones
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID uuid.UUID, codeHashes []string, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO user_recovery_codes (id, user_id, code_hash, created_at)
+			VALUES ($1, $2, $3, $4)`,
+			uuid.New(), userID, hash, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used and reports whether
+// there was one
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_recovery_codes
+		SET used_at = $3
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, codeHash, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,16 +16,32 @@ var (
 	ErrUserNotFound          = errors.New("user not found")
 	ErrEmailAlreadyExists    = errors.New("email already exists")
 	ErrUsernameAlreadyExists = errors.New("username already exists")
+	ErrLastAdmin             = errors.New("cannot remove the last active admin")
 )
 
+// userColumns are the columns scanUser reads, in order
+const userColumns = "id, email, username, fullname, password_hash, role, avatar_url, is_active, token_version, email_verified_at, verification_sent_at, two_factor_enabled_at, failed_login_attempts, last_failed_login_at, locked_until, bio, social_links, created_at, updated_at, deleted_at"
+
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	FindByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
 	FindByUsername(ctx context.Context, username string) (*models.User, error)
-	Update(ctx context.Context, user *models.User) error
+	List(ctx context.Context, filter models.UserFilter, limit, offset int) ([]*models.User, int, error)
+	ListAuthors(ctx context.Context, limit, offset int) ([]*models.User, int, error)
+	CountPosts(ctx context.Context, userIDs []uuid.UUID, status models.PostStatus) (map[uuid.UUID]int64, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, req models.UpdateProfileRequest) (*models.User, error)
+	ChangePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	ChangeEmail(ctx context.Context, userID uuid.UUID, email string) (*models.User, error)
+	UpdateRole(ctx context.Context, userID uuid.UUID, role models.UserRole) (models.UserRole, error)
+	SetActive(ctx context.Context, userID uuid.UUID, active bool) (bool, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	UpdateAvatarURL(ctx context.Context, userID uuid.UUID, avatarURL string) error
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) error
+	ClaimVerificationSend(ctx context.Context, userID uuid.UUID, minInterval time.Duration) (bool, error)
+	RecordLoginFailure(ctx context.Context, userID uuid.UUID, lockAfter int, lockUntil time.Time) (bool, error)
+	ClearLoginFailures(ctx context.Context, userID uuid.UUID) error
+	RevokeSessions(ctx context.Context, userID uuid.UUID) error
 }
 
 type userRepository struct {
@@ -84,7 +102,7 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 
 func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	query := `
-		SELECT id, email, username, fullname, password_hash, role, avatar_url, is_active, created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -93,7 +111,7 @@ func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Us
 
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, email, username, fullname, password_hash, role, avatar_url, is_active, created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = $1 AND deleted_at IS NULL
 	`
@@ -102,18 +120,161 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models
 
 func (r *userRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
 	query := `
-		SELECT id, email, username, fullname, password_hash, role, avatar_url, is_active, created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE username = $1 AND deleted_at IS NULL
 	`
 	return r.findOneByQuery(ctx, query, username)
 }
 
+// List returns live users matching filter, newest first, and the total count
+func (r *userRepository) List(ctx context.Context, filter models.UserFilter, limit, offset int) ([]*models.User, int, error) {
+	whereConditions := []string{"deleted_at IS NULL"}
+	args := []interface{}{}
+	argCount := 0
+
+	if filter.Search != "" {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("(email ILIKE $%d OR username ILIKE $%d)", argCount, argCount))
+		args = append(args, "%"+filter.Search+"%")
+	}
+
+	if filter.Role != "" {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("role = $%d", argCount))
+		args = append(args, filter.Role)
+	}
+
+	if filter.IsActive != nil {
+		argCount++
+		whereConditions = append(whereConditions, fmt.Sprintf("is_active = $%d", argCount))
+		args = append(args, *filter.IsActive)
+	}
+
+	whereClause := strings.Join(whereConditions, " AND ")
+
+	var total int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE "+whereClause, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM users
+		WHERE %s
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d`, userColumns, whereClause, argCount+1, argCount+2)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	return users, total, rows.Err()
+}
+
+// ListAuthors returns active users with at least one published post, by
+// name, and the total count
+func (r *userRepository) ListAuthors(ctx context.Context, limit, offset int) ([]*models.User, int, error) {
+	whereClause := `deleted_at IS NULL AND is_active = true AND EXISTS (
+			SELECT 1 FROM posts p
+			WHERE p.author_id = users.id AND p.status = $1 AND p.deleted_at IS NULL
+		)`
+
+	var total int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE "+whereClause, models.StatusPublished).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM users
+		WHERE %s
+		ORDER BY fullname, username
+		LIMIT $2 OFFSET $3`, userColumns, whereClause)
+
+	rows, err := r.db.QueryContext(ctx, query, models.StatusPublished, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	return users, total, rows.Err()
+}
+
+// CountPosts returns the number of posts outside the trash written by each
+// of the users, limited to status unless it is empty. Users without posts
+// are missing from the map.
+func (r *userRepository) CountPosts(ctx context.Context, userIDs []uuid.UUID, status models.PostStatus) (map[uuid.UUID]int64, error) {
+	counts := make(map[uuid.UUID]int64, len(userIDs))
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	whereConditions := []string{"author_id = ANY($1::uuid[])", "deleted_at IS NULL"}
+	args := []interface{}{uuidStrings(userIDs)}
+	if status != "" {
+		whereConditions = append(whereConditions, "status = $2")
+		args = append(args, status)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT author_id, COUNT(*)
+		FROM posts
+		WHERE `+strings.Join(whereConditions, " AND ")+`
+		GROUP BY author_id`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID uuid.UUID
+		var count int64
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, err
+		}
+		counts[userID] = count
+	}
+
+	return counts, rows.Err()
+}
+
 func (r *userRepository) findOneByQuery(ctx context.Context, query string, args ...interface{}) (*models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+func scanUser(row rowScanner) (*models.User, error) {
 	var user models.User
 	var deletedAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.Username,
@@ -122,15 +283,21 @@ func (r *userRepository) findOneByQuery(ctx context.Context, query string, args
 		&user.Role,
 		&user.AvatarURL,
 		&user.IsActive,
+		&user.TokenVersion,
+		&user.EmailVerifiedAt,
+		&user.VerificationSentAt,
+		&user.TwoFactorEnabledAt,
+		&user.FailedLoginAttempts,
+		&user.LastFailedLoginAt,
+		&user.LockedUntil,
+		&user.Bio,
+		&user.SocialLinks,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&deletedAt,
 	)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrUserNotFound
-		}
 		return nil, err
 	}
 
@@ -141,31 +308,54 @@ func (r *userRepository) findOneByQuery(ctx context.Context, query string, args
 	return &user, nil
 }
 
-func (r *userRepository) Update(ctx context.Context, user *models.User) error {
-	_, err := r.FindByID(ctx, user.ID)
+// UpdateProfile changes the profile fields that are set in req and returns
+// the updated user. Other columns are left alone, so a concurrent role,
+// status or password change is not overwritten.
+func (r *userRepository) UpdateProfile(ctx context.Context, userID uuid.UUID, req models.UpdateProfileRequest) (*models.User, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if req.Username != nil {
+		var count int
+		err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE username = $1 AND id <> $2", *req.Username, userID).Scan(&count)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrUsernameAlreadyExists
+		}
 	}
-	user.UpdatedAt = time.Now()
 
 	query := `
 		UPDATE users
-		SET email = $1, username = $2, fullname = $3, password_hash = $4, role = $5, avatar_url = $6, is_active = $7, updated_at = $8
-		WHERE id = $9 AND deleted_at IS NULL
+		SET fullname = COALESCE($1, fullname), username = COALESCE($2, username), bio = COALESCE($3, bio),
+			social_links = COALESCE($4, social_links), updated_at = $5
+		WHERE id = $6 AND deleted_at IS NULL
+		RETURNING ` + userColumns
+	user, err := scanUser(tx.QueryRowContext(ctx, query,
+		req.Fullname, req.Username, req.Bio, req.SocialLinks, time.Now(), userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, tx.Commit()
+}
+
+// ChangePassword sets a new password hash and revokes every session of the
+// user in the same statement
+func (r *userRepository) ChangePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1, token_version = token_version + 1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
 	`
-	result, err := r.db.ExecContext(
-		ctx,
-		query,
-		user.Email,
-		user.Username,
-		user.Fullname,
-		user.PasswordHash,
-		user.Role,
-		user.AvatarURL,
-		user.IsActive,
-		user.UpdatedAt,
-		user.ID,
-	)
+	result, err := r.db.ExecContext(ctx, query, passwordHash, time.Now(), userID)
 	if err != nil {
 		return err
 	}
@@ -182,13 +372,152 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	return nil
 }
 
+// ChangeEmail moves the user to a new address, which is unverified until the
+// user confirms it, and returns the updated user. It fails with
+// ErrEmailAlreadyExists if another user has the address.
+func (r *userRepository) ChangeEmail(ctx context.Context, userID uuid.UUID, email string) (*models.User, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE email = $1 AND id <> $2", email, userID).Scan(&count)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrEmailAlreadyExists
+	}
+
+	query := `
+		UPDATE users
+		SET email = $1, email_verified_at = NULL, verification_sent_at = NULL, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+		RETURNING ` + userColumns
+	user, err := scanUser(tx.QueryRowContext(ctx, query, email, time.Now(), userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, tx.Commit()
+}
+
+// UpdateRole sets the user's role and returns the previous one. Demoting the
+// last active admin fails with ErrLastAdmin.
+func (r *userRepository) UpdateRole(ctx context.Context, userID uuid.UUID, role models.UserRole) (models.UserRole, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	if role != models.RoleAdmin {
+		if err := keepAnAdmin(ctx, tx, userID); err != nil {
+			return "", err
+		}
+	}
+
+	var oldRole models.UserRole
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(role, 'user')
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE`,
+		userID).Scan(&oldRole)
+	if err == sql.ErrNoRows {
+		return "", ErrUserNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if oldRole != role {
+		_, err = tx.ExecContext(ctx, "UPDATE users SET role = $1, updated_at = $2 WHERE id = $3", role, time.Now(), userID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return oldRole, tx.Commit()
+}
+
+// SetActive activates or deactivates the user and reports whether they were
+// active before. Deactivating revokes their sessions and deletes their
+// personal access tokens in the same transaction, so they stay signed out if
+// reactivated. Deactivating the last active admin fails with ErrLastAdmin.
+func (r *userRepository) SetActive(ctx context.Context, userID uuid.UUID, active bool) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if !active {
+		if err := keepAnAdmin(ctx, tx, userID); err != nil {
+			return false, err
+		}
+	}
+
+	var wasActive bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(is_active, true)
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE`,
+		userID).Scan(&wasActive)
+	if err == sql.ErrNoRows {
+		return false, ErrUserNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if wasActive != active {
+		if active {
+			_, err = tx.ExecContext(ctx, "UPDATE users SET is_active = true, updated_at = $1 WHERE id = $2", time.Now(), userID)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE users
+				SET is_active = false, token_version = token_version + 1, updated_at = $1
+				WHERE id = $2`,
+				time.Now(), userID)
+			if err == nil {
+				_, err = tx.ExecContext(ctx, "DELETE FROM personal_access_tokens WHERE user_id = $1", userID)
+			}
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return wasActive, tx.Commit()
+}
+
+// Delete moves the user to the trash and revokes their sessions, so they
+// stay signed out if restored. It fails with ErrLastAdmin for the last
+// active admin.
 func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := keepAnAdmin(ctx, tx, id); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE users
-		SET deleted_at = $1
+		SET deleted_at = $1, token_version = token_version + 1
 		WHERE id = $2 AND deleted_at IS NULL
 	`
-	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	result, err := tx.ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return err
 	}
@@ -202,6 +531,44 @@ func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		return ErrUserNotFound
 	}
 
+	return tx.Commit()
+}
+
+// keepAnAdmin fails with ErrLastAdmin if userID is the only active admin.
+// The admin rows stay locked until tx ends, so two admins demoting each
+// other at the same time cannot both succeed.
+func keepAnAdmin(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id
+		FROM users
+		WHERE role = $1 AND is_active AND deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE`,
+		models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	isAdmin, others := false, 0
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		if id == userID {
+			isAdmin = true
+		} else {
+			others++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if isAdmin && others == 0 {
+		return ErrLastAdmin
+	}
 	return nil
 }
 
@@ -228,3 +595,121 @@ func (r *userRepository) UpdateAvatarURL(ctx context.Context, userID uuid.UUID,
 
 	return nil
 }
+
+// MarkEmailVerified records that the user confirmed email. It fails with
+// ErrUserNotFound when the user no longer has that address. Verifying twice
+// keeps the first time.
+func (r *userRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) error {
+	query := `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, $1)
+		WHERE id = $2 AND email = $3 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), userID, email)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ClaimVerificationSend records that a verification email is being sent and
+// reports false, without recording, if one was sent within minInterval. The
+// check and update are one statement, so concurrent requests send only once.
+func (r *userRepository) ClaimVerificationSend(ctx context.Context, userID uuid.UUID, minInterval time.Duration) (bool, error) {
+	now := time.Now()
+	query := `
+		UPDATE users
+		SET verification_sent_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+		  AND (verification_sent_at IS NULL OR verification_sent_at <= $3)
+	`
+	result, err := r.db.ExecContext(ctx, query, now, userID, now.Add(-minInterval))
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RecordLoginFailure counts a wrong password. The lockAfter-th consecutive
+// failure locks the account until lockUntil and resets the count; it reports
+// whether this call locked the account.
+func (r *userRepository) RecordLoginFailure(ctx context.Context, userID uuid.UUID, lockAfter int, lockUntil time.Time) (bool, error) {
+	query := `
+		UPDATE users
+		SET failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= $2 THEN 0 ELSE failed_login_attempts + 1 END,
+			locked_until = CASE WHEN failed_login_attempts + 1 >= $2 THEN $3 ELSE locked_until END,
+			last_failed_login_at = $4
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING failed_login_attempts = 0
+	`
+	var locked bool
+	err := r.db.QueryRowContext(ctx, query, userID, lockAfter, lockUntil, time.Now()).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return false, ErrUserNotFound
+	}
+	return locked, err
+}
+
+// ClearLoginFailures resets the failure count and lifts any lockout
+func (r *userRepository) ClearLoginFailures(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET failed_login_attempts = 0, last_failed_login_at = NULL, locked_until = NULL
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// RevokeSessions invalidates every access and refresh token of the user
+func (r *userRepository) RevokeSessions(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET token_version = token_version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
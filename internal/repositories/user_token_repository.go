@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+var (
+	ErrUserTokenInvalid = errors.New("token is invalid, used or expired")
+)
+
+type UserTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+// Replace stores a new token, discarding the user's unused tokens of the same
+// purpose so only the latest one works
+func (r *UserTokenRepository) Replace(ctx context.Context, token *models.UserToken) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM user_tokens
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+		token.UserID, token.Purpose)
+	if err != nil {
+		return err
+	}
+
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	token.CreatedAt = time.Now()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_tokens (id, user_id, purpose, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		token.ID, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// consumeUserToken marks a live token as used within tx and returns its user
+func consumeUserToken(ctx context.Context, tx *sql.Tx, purpose models.UserTokenPurpose, tokenHash string, now time.Time) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := tx.QueryRowContext(ctx, `
+		UPDATE user_tokens
+		SET used_at = $3
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+		RETURNING user_id`,
+		tokenHash, purpose, now,
+	).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, ErrUserTokenInvalid
+	}
+	return userID, err
+}
+
+// ResetPassword consumes a password reset token, sets the new password hash
+// and bumps the user's token version, which revokes all issued JWTs
+func (r *UserTokenRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	userID, err := consumeUserToken(ctx, tx, models.TokenPasswordReset, tokenHash, now)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET password_hash = $2, token_version = token_version + 1, updated_at = $3
+		WHERE id = $1 AND deleted_at IS NULL`,
+		userID, passwordHash, now)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return uuid.Nil, err
+	} else if rows == 0 {
+		return uuid.Nil, ErrUserTokenInvalid
+	}
+
+	// Any other outstanding reset link is now stale
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM user_tokens
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+		userID, models.TokenPasswordReset)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return userID, tx.Commit()
+}
+
+// FindLive returns an unused, unexpired token without consuming it
+func (r *UserTokenRepository) FindLive(ctx context.Context, purpose models.UserTokenPurpose, tokenHash string) (*models.UserToken, error) {
+	var token models.UserToken
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, purpose, token_hash, expires_at, used_at, attempts, created_at
+		FROM user_tokens
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3`,
+		tokenHash, purpose, time.Now(),
+	).Scan(&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &token.ExpiresAt, &token.UsedAt, &token.Attempts, &token.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Consume marks a live token as used. Only one of several concurrent callers
+// succeeds; the others get ErrUserTokenInvalid.
+func (r *UserTokenRepository) Consume(ctx context.Context, purpose models.UserTokenPurpose, tokenHash string) (uuid.UUID, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+
+	userID, err := consumeUserToken(ctx, tx, purpose, tokenHash, time.Now())
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return userID, tx.Commit()
+}
+
+// RecordFailedAttempt counts a failed use of a token and invalidates it once
+// maxAttempts is reached
+func (r *UserTokenRepository) RecordFailedAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE user_tokens
+		SET attempts = attempts + 1,
+			used_at = CASE WHEN attempts + 1 >= $2 THEN $3 ELSE used_at END
+		WHERE id = $1 AND used_at IS NULL`,
+		id, maxAttempts, time.Now())
+	return err
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+)
+
+var (
+	ErrInvalidAnalyticsRange = errors.New("invalid analytics date range")
+)
+
+const (
+	// defaultAnalyticsDays is the range used when none is given, ending today
+	defaultAnalyticsDays = 7
+	maxAnalyticsDays     = 366
+	defaultAnalyticsTop  = 10
+	maxAnalyticsTop      = 100
+)
+
+// AnalyticsService reports on the daily view aggregates. Ranges are given as
+// YYYY-MM-DD dates, inclusive, in the blog's timezone; empty dates default to
+// the last seven days.
+type AnalyticsService interface {
+	GetSummary(ctx context.Context, from, to string, limit int) (*models.AnalyticsSummary, error)
+	GetPostSeries(ctx context.Context, postID uuid.UUID, from, to string, limit int) (*models.PostViewSeries, error)
+}
+
+type analyticsService struct {
+	repo     *repositories.AnalyticsRepository
+	postRepo *repositories.PostRepository
+	location *time.Location
+}
+
+func NewAnalyticsService(repo *repositories.AnalyticsRepository, postRepo *repositories.PostRepository, location *time.Location) AnalyticsService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &analyticsService{
+		repo:     repo,
+		postRepo: postRepo,
+		location: location,
+	}
+}
+
+func (s *analyticsService) GetSummary(ctx context.Context, from, to string, limit int) (*models.AnalyticsSummary, error) {
+	from, to, err := s.dateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	limit = analyticsLimit(limit)
+
+	summary := &models.AnalyticsSummary{From: from, To: to}
+	if summary.TotalViews, err = s.repo.TotalViews(from, to); err != nil {
+		return nil, err
+	}
+	if summary.TopPosts, err = s.repo.TopPosts(from, to, limit); err != nil {
+		return nil, err
+	}
+	if summary.TopCategories, err = s.repo.TopCategories(from, to, limit); err != nil {
+		return nil, err
+	}
+	if summary.TopReferrers, err = s.repo.TopReferrers(nil, from, to, limit); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func (s *analyticsService) GetPostSeries(ctx context.Context, postID uuid.UUID, from, to string, limit int) (*models.PostViewSeries, error) {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
+
+	from, to, err = s.dateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &models.PostViewSeries{PostID: postID, From: from, To: to}
+	if series.Days, err = s.repo.PostDailyViews(postID, from, to); err != nil {
+		return nil, err
+	}
+	for _, day := range series.Days {
+		series.TotalViews += day.Views
+	}
+	if series.TopReferrers, err = s.repo.TopReferrers(&postID, from, to, analyticsLimit(limit)); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// dateRange validates the range and fills in the defaults
+func (s *analyticsService) dateRange(from, to string) (string, string, error) {
+	end := time.Now().In(s.location)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	if to != "" {
+		parsed, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			return "", "", ErrInvalidAnalyticsRange
+		}
+		end = parsed
+	}
+
+	start := end.AddDate(0, 0, -(defaultAnalyticsDays - 1))
+	if from != "" {
+		parsed, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			return "", "", ErrInvalidAnalyticsRange
+		}
+		start = parsed
+	}
+
+	if start.After(end) || end.Sub(start) >= maxAnalyticsDays*24*time.Hour {
+		return "", "", ErrInvalidAnalyticsRange
+	}
+
+	return start.Format(time.DateOnly), end.Format(time.DateOnly), nil
+}
+
+func analyticsLimit(limit int) int {
+	if limit < 1 {
+		return defaultAnalyticsTop
+	}
+	if limit > maxAnalyticsTop {
+		return maxAnalyticsTop
+	}
+	return limit
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+)
+
+var (
+	ErrInvalidArchivePeriod = errors.New("invalid archive year or month")
+)
+
+// ArchiveService groups published posts by month of publication
+type ArchiveService interface {
+	GetMonths(ctx context.Context) (*models.ArchiveResponse, error)
+	GetPosts(ctx context.Context, year, month, page, pageSize int) (*models.PaginatedPostResponse, error)
+}
+
+type archiveService struct {
+	repo        *repositories.PostRepository
+	postService PostService
+	location    *time.Location
+}
+
+// NewArchiveService creates an archive service whose months are calendar
+// months in location
+func NewArchiveService(repo *repositories.PostRepository, postService PostService, location *time.Location) ArchiveService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &archiveService{
+		repo:        repo,
+		postService: postService,
+		location:    location,
+	}
+}
+
+func (s *archiveService) GetMonths(ctx context.Context) (*models.ArchiveResponse, error) {
+	months, err := s.repo.GetArchiveMonths(s.location.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ArchiveResponse{
+		Timezone: s.location.String(),
+		Months:   months,
+	}, nil
+}
+
+// GetPosts lists the posts published in the given month, newest first
+func (s *archiveService) GetPosts(ctx context.Context, year, month, page, pageSize int) (*models.PaginatedPostResponse, error) {
+	if year < 1 || year > 9999 || month < 1 || month > 12 {
+		return nil, ErrInvalidArchivePeriod
+	}
+
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, s.location)
+	end := start.AddDate(0, 1, 0)
+
+	return s.postService.GetAll(ctx, &models.PostFilter{
+		Status:          models.StatusPublished,
+		PublishedFrom:   &start,
+		PublishedBefore: &end,
+		SortBy:          "published_at",
+		SortDesc:        true,
+	}, page, pageSize)
+}
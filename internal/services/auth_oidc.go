@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/services/oidc"
+	"github.com/kyomel/blog-management/internal/utils"
+)
+
+var (
+	ErrOIDCProviderNotFound = errors.New("unknown login provider")
+	ErrInvalidOIDCState     = errors.New("login state is invalid or expired")
+	ErrOIDCDenied           = errors.New("login was denied at the provider")
+	ErrOIDCLoginFailed      = errors.New("login with the provider failed")
+	ErrOIDCEmailRequired    = errors.New("the provider did not share an email address")
+	ErrOIDCEmailUnverified  = errors.New("the provider has not verified the email address of an existing account")
+)
+
+// oidcStateTTL is how long a user has to complete a login at the provider
+const oidcStateTTL = 10 * time.Minute
+
+// OIDCProvider is an OpenID provider users may log in with
+type OIDCProvider struct {
+	*oidc.Provider
+	// DefaultRole is given to users created on their first login
+	DefaultRole models.UserRole
+}
+
+// OIDCProviders lists the names of the configured providers
+func (s *authService) OIDCProviders() []string {
+	names := make([]string, 0, len(s.oidcProviders))
+	for name := range s.oidcProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OIDCAuthURL starts a login: it remembers a fresh state, nonce and PKCE
+// verifier and returns the provider URL to redirect the user to
+func (s *authService) OIDCAuthURL(ctx context.Context, providerName string) (string, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return "", ErrOIDCProviderNotFound
+	}
+
+	var values [3]string
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			return "", err
+		}
+		values[i] = value
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	err := s.oidcRepo.SaveState(ctx, &models.OIDCLoginState{
+		StateHash:    utils.HashToken(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return provider.AuthCodeURL(ctx, state, nonce, verifier)
+}
+
+// OIDCCallback completes a login. The provider account is matched by its
+// subject, then by verified email; otherwise a new user is created with the
+// provider's default role.
+func (s *authService) OIDCCallback(ctx context.Context, providerName string, req models.OIDCCallbackRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+	if req.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrOIDCDenied, req.Error, req.ErrorDescription)
+	}
+
+	state, err := s.oidcRepo.ConsumeState(ctx, providerName, utils.HashToken(req.State))
+	if errors.Is(err, repositories.ErrOIDCStateInvalid) {
+		return nil, ErrInvalidOIDCState
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := provider.Exchange(ctx, req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	user, err := s.oidcUser(ctx, provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		s.auditLogin(ctx, &user.ID, models.ActionLoginFailed, client, user.Email, "inactive")
+		return nil, ErrUserNotActive
+	}
+	if s.blockedUnverified(user) {
+		s.auditLogin(ctx, &user.ID, models.ActionLoginFailed, client, user.Email, "email_not_verified")
+		return nil, ErrEmailNotVerified
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		s.auditLogin(ctx, &user.ID, models.ActionLogin, client, user.Email, "oidc_"+providerName+"_two_factor_pending")
+		return s.loginChallenge(ctx, user)
+	}
+
+	s.auditLogin(ctx, &user.ID, models.ActionLogin, client, user.Email, "oidc_"+providerName)
+	return s.authResponse(user)
+}
+
+// oidcUser finds or creates the user for a provider account and links them
+func (s *authService) oidcUser(ctx context.Context, provider OIDCProvider, claims *oidc.Claims) (*models.User, error) {
+	identity, err := s.oidcRepo.FindIdentity(ctx, provider.Name(), claims.Subject)
+	if err == nil {
+		if err := s.oidcRepo.TouchIdentity(ctx, identity.ID, claims.Email); err != nil {
+			return nil, err
+		}
+		user, err := s.userRepo.FindByID(ctx, identity.UserID)
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return nil, ErrUserNotActive
+		}
+		return user, err
+	}
+	if !errors.Is(err, repositories.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" {
+		return nil, ErrOIDCEmailRequired
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		// Linking on an unverified address would let anyone who can set an
+		// email at the provider take over the local account
+		if !claims.EmailVerified {
+			return nil, ErrOIDCEmailUnverified
+		}
+	case errors.Is(err, repositories.ErrUserNotFound):
+		user, err = s.provisionOIDCUser(ctx, provider, claims)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.oidcRepo.CreateIdentity(ctx, &models.UserIdentity{
+		UserID:      user.ID,
+		Provider:    provider.Name(),
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: &now,
+	})
+	if err != nil && !errors.Is(err, repositories.ErrIdentityLinked) {
+		return nil, err
+	}
+
+	if claims.EmailVerified && user.EmailVerifiedAt == nil {
+		if err := s.userRepo.MarkEmailVerified(ctx, user.ID, user.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.userRepo.FindByID(ctx, user.ID)
+}
+
+// provisionOIDCUser creates a user for a provider account. The password is
+// random and never shown, so the user can only log in through the provider
+// until they reset it.
+func (s *authService) provisionOIDCUser(ctx context.Context, provider OIDCProvider, claims *oidc.Claims) (*models.User, error) {
+	password, err := utils.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	base := usernameFromClaims(claims)
+	fullname := strings.TrimSpace(claims.Name)
+	if fullname == "" {
+		fullname = base
+	}
+
+	for attempt := 0; ; attempt++ {
+		username := base
+		if attempt > 0 {
+			username = fmt.Sprintf("%s-%04d", base, rand.IntN(10000))
+		}
+
+		user := &models.User{
+			Email:        claims.Email,
+			Fullname:     fullname,
+			Username:     username,
+			PasswordHash: hashedPassword,
+			Role:         provider.DefaultRole,
+			AvatarURL:    claims.Picture,
+			IsActive:     true,
+		}
+		err := s.userRepo.Create(ctx, user)
+		if errors.Is(err, repositories.ErrUsernameAlreadyExists) && attempt < 5 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !claims.EmailVerified {
+			if err := s.sendVerification(ctx, user); err != nil {
+				log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+			}
+		}
+		return user, nil
+	}
+}
+
+// usernameFromClaims derives a username from the preferred username or the
+// local part of the email, keeping only characters safe in URLs
+func usernameFromClaims(claims *oidc.Claims) string {
+	source := claims.PreferredUsername
+	if source == "" {
+		source, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(source) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '_' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+
+	username := b.String()
+	if len(username) > 40 {
+		username = username[:40]
+	}
+	if len(username) < 3 {
+		username = "user" + username
+	}
+	return username
+}
@@ -3,44 +3,139 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/kyomel/blog-management/internal/models"
 	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/services/mailer"
 	"github.com/kyomel/blog-management/internal/utils"
 )
 
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserNotActive      = errors.New("user account is not active")
+	ErrInvalidResetToken  = errors.New("invalid or expired password reset token")
+	ErrEmailNotVerified   = errors.New("email address is not verified")
+	ErrInvalidEmailToken  = errors.New("invalid or expired email verification token")
+	ErrUserNotFound       = errors.New("user not found")
 )
 
 type AuthService interface {
 	Register(ctx context.Context, req models.RegisterRequest) (*models.AuthResponse, error)
-	Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error)
+	Login(ctx context.Context, req models.LoginRequest, client models.ClientInfo) (*models.AuthResponse, error)
 	RefreshToken(ctx context.Context, refreshToken string) (*models.AuthResponse, error)
-	ValidateToken(tokenString string) (*utils.JWTClaims, error)
+	ValidateToken(ctx context.Context, tokenString string) (*utils.JWTClaims, error)
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, req models.ResetPasswordRequest) error
+	ResendVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+	VerifyTwoFactor(ctx context.Context, req models.TwoFactorLoginRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	SetupTwoFactor(ctx context.Context, userID uuid.UUID) (*models.TwoFactorSetupResponse, error)
+	ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, code string) (*models.TwoFactorEnabledResponse, error)
+	DisableTwoFactor(ctx context.Context, userID uuid.UUID, req models.TwoFactorCodeRequest, client models.ClientInfo) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string, client models.ClientInfo) ([]string, error)
+	UnlockAccount(ctx context.Context, userID, adminID uuid.UUID, client models.ClientInfo) error
+	OIDCProviders() []string
+	OIDCAuthURL(ctx context.Context, provider string) (string, error)
+	OIDCCallback(ctx context.Context, provider string, req models.OIDCCallbackRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	JWKS() utils.JWKSet
+}
+
+// PasswordResetConfig controls the emailed password reset links
+type PasswordResetConfig struct {
+	// URL is the frontend page that receives the token as ?token=
+	URL string
+	TTL time.Duration
+}
+
+// UnverifiedPolicy decides what users without a verified email may do
+type UnverifiedPolicy string
+
+const (
+	UnverifiedAllow    UnverifiedPolicy = "allow"
+	UnverifiedReadOnly UnverifiedPolicy = "read_only"
+	UnverifiedBlock    UnverifiedPolicy = "block"
+)
+
+// EmailVerificationConfig controls the emailed verification links
+type EmailVerificationConfig struct {
+	// URL is the frontend page that receives the token as ?token=
+	URL            string
+	Policy         UnverifiedPolicy
+	ResendInterval time.Duration
 }
 
 type authService struct {
-	userRepo     repositories.UserRepository
-	jwtService   utils.JWTService
-	accessExpiry time.Duration
+	userRepo          repositories.UserRepository
+	userTokenRepo     *repositories.UserTokenRepository
+	twoFactorRepo     *repositories.TwoFactorRepository
+	auditRepo         *repositories.AuditRepository
+	oidcRepo          *repositories.OIDCRepository
+	personalTokenRepo *repositories.PersonalTokenRepository
+	jwtService        utils.JWTService
+	emailTokens       utils.EmailTokenService
+	mailer            mailer.Mailer
+	accessExpiry      time.Duration
+	passwordReset     PasswordResetConfig
+	emailVerification EmailVerificationConfig
+	twoFactor         TwoFactorConfig
+	loginThrottle     LoginThrottleConfig
+	ipThrottle        *ipThrottle
+	unknownEmails     *unknownEmailThrottle
+	oidcProviders     map[string]OIDCProvider
 }
 
 func NewAuthService(
 	userRepo repositories.UserRepository,
+	userTokenRepo *repositories.UserTokenRepository,
+	twoFactorRepo *repositories.TwoFactorRepository,
+	auditRepo *repositories.AuditRepository,
+	oidcRepo *repositories.OIDCRepository,
+	personalTokenRepo *repositories.PersonalTokenRepository,
 	jwtService utils.JWTService,
+	emailTokens utils.EmailTokenService,
+	mailer mailer.Mailer,
 	accessExpiry time.Duration,
+	passwordReset PasswordResetConfig,
+	emailVerification EmailVerificationConfig,
+	twoFactor TwoFactorConfig,
+	loginThrottle LoginThrottleConfig,
+	oidcProviders []OIDCProvider,
 ) AuthService {
+	providers := make(map[string]OIDCProvider, len(oidcProviders))
+	for _, provider := range oidcProviders {
+		providers[provider.Name()] = provider
+	}
+
 	return &authService{
-		userRepo:     userRepo,
-		jwtService:   jwtService,
-		accessExpiry: accessExpiry,
+		userRepo:          userRepo,
+		userTokenRepo:     userTokenRepo,
+		twoFactorRepo:     twoFactorRepo,
+		auditRepo:         auditRepo,
+		oidcRepo:          oidcRepo,
+		personalTokenRepo: personalTokenRepo,
+		jwtService:        jwtService,
+		emailTokens:       emailTokens,
+		mailer:            mailer,
+		accessExpiry:      accessExpiry,
+		passwordReset:     passwordReset,
+		emailVerification: emailVerification,
+		twoFactor:         twoFactor,
+		loginThrottle:     loginThrottle,
+		ipThrottle:        newIPThrottle(loginThrottle),
+		unknownEmails:     newUnknownEmailThrottle(loginThrottle),
+		oidcProviders:     providers,
 	}
 }
 
+// Register creates the account and emails a verification link. Tokens are
+// only issued when unverified users are allowed to log in.
 func (s *authService) Register(ctx context.Context, req models.RegisterRequest) (*models.AuthResponse, error) {
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
@@ -60,118 +155,368 @@ func (s *authService) Register(ctx context.Context, req models.RegisterRequest)
 		return nil, err
 	}
 
-	tokens, err := s.jwtService.GenerateTokenPair(
-		user.ID,
-		user.Username,
-		user.Email,
-		string(user.Role),
-	)
-	if err != nil {
-		return nil, err
+	if err := s.sendVerification(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
 	}
 
-	return &models.AuthResponse{
-		User: models.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			Username:  user.Username,
-			Fullname:  user.Fullname,
-			Role:      user.Role,
-			AvatarURL: user.AvatarURL,
-			IsActive:  user.IsActive,
-			CreatedAt: user.CreatedAt,
-		},
-		AccessToken:  tokens.AccessToken,
-		RefreshToken: tokens.RefreshToken,
-		ExpiresIn:    int64(s.accessExpiry.Seconds()),
-	}, nil
+	if s.emailVerification.Policy == UnverifiedBlock {
+		return &models.AuthResponse{User: userResponse(user)}, nil
+	}
+	return s.authResponse(user)
 }
 
-func (s *authService) Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error) {
+// Login checks the password. Failures slow down further attempts from the
+// same IP and for the same account, and enough of them lock the account.
+// Every attempt is written to the audit log.
+func (s *authService) Login(ctx context.Context, req models.LoginRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	now := time.Now()
+	if wait := s.ipThrottle.retryAfter(client.IPAddress, now); wait > 0 {
+		s.auditLogin(ctx, nil, models.ActionLoginFailed, client, req.Email, "ip_throttled")
+		return nil, &RetryError{Err: ErrTooManyAttempts, RetryAfter: wait}
+	}
+
+	// Until the password is verified, every response and its timing has to
+	// be the same whether or not the email belongs to an account
 	user, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, repositories.ErrUserNotFound) {
-			return nil, ErrInvalidCredentials
+			return nil, s.unknownEmailFailed(ctx, req, client, now)
 		}
 		return nil, err
 	}
 
+	if err := s.checkAccountThrottle(ctx, user, client, now); err != nil {
+		return nil, err
+	}
+
+	err = utils.VerifyPassword(user.PasswordHash, req.Password)
+	if err != nil {
+		return nil, s.loginFailed(ctx, user, client, now, "wrong_password")
+	}
+
 	if !user.IsActive {
+		s.auditLogin(ctx, &user.ID, models.ActionLoginFailed, client, req.Email, "inactive")
 		return nil, ErrUserNotActive
 	}
 
-	err = utils.VerifyPassword(user.PasswordHash, req.Password)
+	// With two-factor authentication the failures are only cleared once the
+	// second factor is verified too, or every new challenge would reset the
+	// lockout for someone guessing codes
+	if user.TwoFactorEnabledAt == nil {
+		if err := s.clearLoginFailures(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.blockedUnverified(user) {
+		s.auditLogin(ctx, &user.ID, models.ActionLoginFailed, client, req.Email, "email_not_verified")
+		return nil, ErrEmailNotVerified
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		s.auditLogin(ctx, &user.ID, models.ActionLogin, client, req.Email, "two_factor_pending")
+		return s.loginChallenge(ctx, user)
+	}
+
+	s.auditLogin(ctx, &user.ID, models.ActionLogin, client, req.Email, "")
+	return s.authResponse(user)
+}
+
+// dummyPasswordHash is compared against for unknown emails so that they take
+// as long to reject as a wrong password
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, err := utils.HashPassword("not-a-real-password")
+	if err != nil {
+		log.Printf("Failed to hash dummy password: %v", err)
+	}
+	return hash
+})
+
+// unknownEmailFailed rejects a login for an email without an account the
+// same way a wrong password is rejected
+func (s *authService) unknownEmailFailed(ctx context.Context, req models.LoginRequest, client models.ClientInfo, now time.Time) error {
+	if err := s.unknownEmails.check(req.Email, now); err != nil {
+		s.auditLogin(ctx, nil, models.ActionLoginFailed, client, req.Email, "unknown_email_throttled")
+		return err
+	}
+
+	_ = utils.VerifyPassword(dummyPasswordHash(), req.Password)
+
+	s.ipThrottle.fail(client.IPAddress, now)
+	s.unknownEmails.fail(req.Email, now)
+	s.auditLogin(ctx, nil, models.ActionLoginFailed, client, req.Email, "unknown_email")
+	return ErrInvalidCredentials
+}
+
+func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*models.AuthResponse, error) {
+	claims, err := s.jwtService.ValidateRefreshToken(refreshToken)
 	if err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, err
 	}
 
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrUserNotActive
+	}
+
+	if claims.TokenVersion != user.TokenVersion {
+		return nil, utils.ErrInvalidToken
+	}
+
+	if s.blockedUnverified(user) {
+		return nil, ErrEmailNotVerified
+	}
+
+	return s.authResponse(user)
+}
+
+// authResponse issues a new token pair for user
+func (s *authService) authResponse(user *models.User) (*models.AuthResponse, error) {
 	tokens, err := s.jwtService.GenerateTokenPair(
 		user.ID,
 		user.Username,
 		user.Email,
 		string(user.Role),
+		user.TokenVersion,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.AuthResponse{
-		User: models.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			Username:  user.Username,
-			Role:      user.Role,
-			AvatarURL: user.AvatarURL,
-			IsActive:  user.IsActive,
-			CreatedAt: user.CreatedAt,
-		},
-		AccessToken:  tokens.AccessToken,
-		RefreshToken: tokens.RefreshToken,
-		ExpiresIn:    int64(s.accessExpiry.Seconds()),
+		User:                   userResponse(user),
+		AccessToken:            tokens.AccessToken,
+		RefreshToken:           tokens.RefreshToken,
+		ExpiresIn:              int64(s.accessExpiry.Seconds()),
+		TwoFactorSetupRequired: s.twoFactorSetupRequired(user),
 	}, nil
 }
 
-func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*models.AuthResponse, error) {
-	claims, err := s.jwtService.ValidateToken(refreshToken)
-	if err != nil {
-		return nil, err
+func userResponse(user *models.User) models.UserResponse {
+	return models.UserResponse{
+		ID:               user.ID,
+		Email:            user.Email,
+		Username:         user.Username,
+		Fullname:         user.Fullname,
+		Role:             user.Role,
+		AvatarURL:        user.AvatarURL,
+		Bio:              user.Bio,
+		SocialLinks:      user.SocialLinks,
+		IsActive:         user.IsActive,
+		EmailVerified:    user.EmailVerifiedAt != nil,
+		TwoFactorEnabled: user.TwoFactorEnabledAt != nil,
+		CreatedAt:        user.CreatedAt,
 	}
+}
 
-	userID, err := uuid.Parse(claims.Subject)
+func (s *authService) blockedUnverified(user *models.User) bool {
+	return s.emailVerification.Policy == UnverifiedBlock && user.EmailVerifiedAt == nil
+}
+
+// ValidateToken checks the token's signature and expiry and that it has not
+// been revoked since it was issued. Personal access tokens are looked up
+// instead.
+func (s *authService) ValidateToken(ctx context.Context, tokenString string) (*utils.JWTClaims, error) {
+	if strings.HasPrefix(tokenString, utils.PersonalTokenPrefix) {
+		return s.validatePersonalToken(ctx, tokenString)
+	}
+
+	claims, err := s.jwtService.ValidateToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := s.userRepo.FindByID(ctx, userID)
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, utils.ErrInvalidToken
+	}
 	if err != nil {
 		return nil, err
 	}
+	if !user.IsActive || claims.TokenVersion != user.TokenVersion {
+		return nil, utils.ErrInvalidToken
+	}
+	if s.blockedUnverified(user) {
+		return nil, ErrEmailNotVerified
+	}
+
+	// The role is read from the user so that role changes apply at once
+	claims.Role = string(user.Role)
+	claims.EmailVerified = user.EmailVerifiedAt != nil
+	claims.TwoFactorSetupRequired = s.twoFactorSetupRequired(user)
+	return claims, nil
+}
 
+// JWKS returns the public keys access tokens can be verified with
+func (s *authService) JWKS() utils.JWKSet {
+	return s.jwtService.JWKS()
+}
+
+// ForgotPassword emails a password reset link if the email belongs to an
+// active user. It does not reveal whether it did: unknown addresses are not
+// an error, and the token is stored and the email sent in the background so
+// response times match.
+func (s *authService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 	if !user.IsActive {
-		return nil, ErrUserNotActive
+		return nil
 	}
 
-	tokens, err := s.jwtService.RefreshTokens(refreshToken)
+	go s.sendPasswordReset(user)
+	return nil
+}
+
+// sendPasswordReset replaces the user's reset token and emails the link.
+// It runs after the request has been answered, so failures are only logged.
+func (s *authService) sendPasswordReset(user *models.User) {
+	ctx := context.Background()
+
+	token, err := utils.GenerateToken()
 	if err != nil {
-		return nil, err
+		log.Printf("Failed to generate password reset token for user %s: %v", user.ID, err)
+		return
 	}
 
-	return &models.AuthResponse{
-		User: models.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			Username:  user.Username,
-			Role:      user.Role,
-			AvatarURL: user.AvatarURL,
-			IsActive:  user.IsActive,
-			CreatedAt: user.CreatedAt,
-		},
-		AccessToken:  tokens.AccessToken,
-		RefreshToken: tokens.RefreshToken,
-		ExpiresIn:    int64(s.accessExpiry.Seconds()),
-	}, nil
+	err = s.userTokenRepo.Replace(ctx, &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   models.TokenPasswordReset,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.passwordReset.TTL),
+	})
+	if err != nil {
+		log.Printf("Failed to store password reset token for user %s: %v", user.ID, err)
+		return
+	}
+
+	link, err := tokenLink(s.passwordReset.URL, token)
+	if err != nil {
+		log.Printf("Failed to build password reset link for user %s: %v", user.ID, err)
+		return
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password of your account. "+
+				"To choose a new password, open this link within %s:\n\n%s\n\n"+
+				"If this was not you, you can ignore this email.\n",
+			user.Username, s.passwordReset.TTL, link),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send password reset email to user %s: %v", user.ID, err)
+	}
 }
 
-func (s *authService) ValidateToken(tokenString string) (*utils.JWTClaims, error) {
-	return s.jwtService.ValidateToken(tokenString)
+// ResetPassword sets a new password using an emailed token. Every session of
+// the user is revoked.
+func (s *authService) ResetPassword(ctx context.Context, req models.ResetPasswordRequest) error {
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.userTokenRepo.ResetPassword(ctx, utils.HashToken(req.Token), hashedPassword)
+	if errors.Is(err, repositories.ErrUserTokenInvalid) {
+		return ErrInvalidResetToken
+	}
+	return err
+}
+
+// ResendVerification emails a new verification link to an unverified user.
+// Like ForgotPassword it does not reveal whether the address has an account,
+// and requests within the resend interval are silently dropped.
+func (s *authService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !user.IsActive || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.sendVerification(ctx, user)
+}
+
+// VerifyEmail marks the address in the token as verified
+func (s *authService) VerifyEmail(ctx context.Context, token string) error {
+	userID, email, err := s.emailTokens.Validate(token)
+	if err != nil {
+		return ErrInvalidEmailToken
+	}
+
+	err = s.userRepo.MarkEmailVerified(ctx, userID, email)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return ErrInvalidEmailToken
+	}
+	return err
+}
+
+// sendVerification emails a verification link unless one was sent within the
+// resend interval
+func (s *authService) sendVerification(ctx context.Context, user *models.User) error {
+	claimed, err := s.userRepo.ClaimVerificationSend(ctx, user.ID, s.emailVerification.ResendInterval)
+	if err != nil || !claimed {
+		return err
+	}
+
+	token, err := s.emailTokens.Generate(user.ID, user.Email)
+	if err != nil {
+		return err
+	}
+
+	link, err := tokenLink(s.emailVerification.URL, token)
+	if err != nil {
+		return err
+	}
+
+	s.sendInBackground(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm that this is your email address by opening this link:\n\n%s\n\n"+
+				"If you did not create an account, you can ignore this email.\n",
+			user.Username, link),
+	}, "verification", user.ID)
+	return nil
+}
+
+// sendInBackground sends msg without making the request wait for the mail
+// server, so response times do not depend on whether an email was sent
+func (s *authService) sendInBackground(msg mailer.Message, kind string, userID uuid.UUID) {
+	go func() {
+		if err := s.mailer.Send(context.Background(), msg); err != nil {
+			log.Printf("Failed to send %s email to user %s: %v", kind, userID, err)
+		}
+	}()
+}
+
+// tokenLink appends token as the token query parameter of base
+func tokenLink(base, token string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
 }
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/utils"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp       = errors.New("two-factor authentication has not been set up")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required for this role")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrInvalidChallenge        = errors.New("invalid or expired two-factor challenge")
+)
+
+const (
+	recoveryCodeCount = 10
+	// maxChallengeAttempts is how many wrong codes a login challenge survives
+	maxChallengeAttempts = 5
+)
+
+// TwoFactorConfig controls TOTP two-factor authentication
+type TwoFactorConfig struct {
+	// Issuer is the account name shown in authenticator apps
+	Issuer       string
+	ChallengeTTL time.Duration
+	// RequiredRoles must enable two-factor authentication before they can use
+	// anything but the enrolment endpoints
+	RequiredRoles []models.UserRole
+}
+
+func (s *authService) twoFactorRequired(user *models.User) bool {
+	return slices.Contains(s.twoFactor.RequiredRoles, user.Role)
+}
+
+// twoFactorSetupRequired reports whether the user must enrol before using
+// the API
+func (s *authService) twoFactorSetupRequired(user *models.User) bool {
+	return user.TwoFactorEnabledAt == nil && s.twoFactorRequired(user)
+}
+
+// loginChallenge answers a correct password of a user with two-factor
+// authentication enabled. Starting a new challenge invalidates the previous one.
+func (s *authService) loginChallenge(ctx context.Context, user *models.User) (*models.AuthResponse, error) {
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.userTokenRepo.Replace(ctx, &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   models.TokenTwoFactorChallenge,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(s.twoFactor.ChallengeTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthResponse{
+		User:              userResponse(user),
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+	}, nil
+}
+
+// VerifyTwoFactor exchanges a login challenge and a TOTP or recovery code for
+// a token pair. A challenge is discarded after too many wrong codes, and
+// wrong codes count towards the account lockout and the IP throttle like
+// wrong passwords, so fresh challenges do not allow unlimited guessing.
+func (s *authService) VerifyTwoFactor(ctx context.Context, req models.TwoFactorLoginRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	now := time.Now()
+	if wait := s.ipThrottle.retryAfter(client.IPAddress, now); wait > 0 {
+		s.auditLogin(ctx, nil, models.ActionLoginFailed, client, "", "ip_throttled")
+		return nil, &RetryError{Err: ErrTooManyAttempts, RetryAfter: wait}
+	}
+
+	tokenHash := utils.HashToken(req.ChallengeToken)
+	challenge, err := s.userTokenRepo.FindLive(ctx, models.TokenTwoFactorChallenge, tokenHash)
+	if errors.Is(err, repositories.ErrUserTokenInvalid) {
+		return nil, ErrInvalidChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, challenge.UserID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, ErrInvalidChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAccountThrottle(ctx, user, client, now); err != nil {
+		return nil, err
+	}
+
+	err = s.checkSecondFactor(ctx, user.ID, req.Code, req.RecoveryCode, true)
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		if recordErr := s.userTokenRepo.RecordFailedAttempt(ctx, challenge.ID, maxChallengeAttempts); recordErr != nil {
+			return nil, recordErr
+		}
+		if failErr := s.loginFailed(ctx, user, client, now, "wrong_two_factor_code"); !errors.Is(failErr, ErrInvalidCredentials) {
+			return nil, failErr
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userTokenRepo.Consume(ctx, models.TokenTwoFactorChallenge, tokenHash); err != nil {
+		if errors.Is(err, repositories.ErrUserTokenInvalid) {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrUserNotActive
+	}
+	if err := s.clearLoginFailures(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.auditLogin(ctx, &user.ID, models.ActionLogin, client, user.Email, "two_factor")
+	return s.authResponse(user)
+}
+
+// SetupTwoFactor creates a new pending TOTP secret. It has no effect on
+// login until ConfirmTwoFactor is called with a code generated from it.
+func (s *authService) SetupTwoFactor(ctx context.Context, userID uuid.UUID) (*models.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.twoFactorRepo.SavePendingTOTP(ctx, userID, secret)
+	if errors.Is(err, repositories.ErrTOTPAlreadyEnabled) {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(s.twoFactor.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication with the first code from
+// the pending secret. Every existing session is revoked, so a new token pair
+// is returned along with the recovery codes.
+func (s *authService) ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, code string) (*models.TwoFactorEnabledResponse, error) {
+	totp, err := s.twoFactorRepo.GetTOTP(ctx, userID)
+	if errors.Is(err, repositories.ErrTOTPNotFound) {
+		return nil, ErrTwoFactorNotSetUp
+	}
+	if err != nil {
+		return nil, err
+	}
+	if totp.ConfirmedAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := utils.ValidateTOTP(totp.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.twoFactorRepo.EnableTOTP(ctx, userID, step, hashes)
+	switch {
+	case errors.Is(err, repositories.ErrTOTPNotFound):
+		// The step was used already, or the secret was replaced meanwhile
+		return nil, ErrInvalidTwoFactorCode
+	case errors.Is(err, repositories.ErrTOTPAlreadyEnabled):
+		return nil, ErrTwoFactorAlreadyEnabled
+	case err != nil:
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := s.authResponse(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorEnabledResponse{AuthResponse: *auth, RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking a TOTP
+// or recovery code. Roles that require it cannot turn it off.
+func (s *authService) DisableTwoFactor(ctx context.Context, userID uuid.UUID, req models.TwoFactorCodeRequest, client models.ClientInfo) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.TwoFactorEnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+	if s.twoFactorRequired(user) {
+		return ErrTwoFactorRequired
+	}
+
+	if err := s.checkSignedInSecondFactor(ctx, user, client, req.Code, req.RecoveryCode, true); err != nil {
+		return err
+	}
+
+	return s.twoFactorRepo.DisableTOTP(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a TOTP
+// code. Recovery codes are not accepted, so a leaked one cannot be used to
+// mint more.
+func (s *authService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string, client models.ClientInfo) ([]string, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSignedInSecondFactor(ctx, user, client, code, "", false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkSignedInSecondFactor is checkSecondFactor for a signed-in user. Wrong
+// codes count towards the account lockout and the IP throttle like they do
+// at login, so a stolen session cannot be used to guess the code.
+func (s *authService) checkSignedInSecondFactor(ctx context.Context, user *models.User, client models.ClientInfo, code, recoveryCode string, allowRecovery bool) error {
+	now := time.Now()
+	if wait := s.ipThrottle.retryAfter(client.IPAddress, now); wait > 0 {
+		s.auditLogin(ctx, &user.ID, models.ActionLoginFailed, client, user.Email, "ip_throttled")
+		return &RetryError{Err: ErrTooManyAttempts, RetryAfter: wait}
+	}
+	if err := s.checkAccountThrottle(ctx, user, client, now); err != nil {
+		return err
+	}
+
+	err := s.checkSecondFactor(ctx, user.ID, code, recoveryCode, allowRecovery)
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		if failErr := s.loginFailed(ctx, user, client, now, "wrong_two_factor_code"); !errors.Is(failErr, ErrInvalidCredentials) {
+			return failErr
+		}
+	}
+	return err
+}
+
+// checkSecondFactor accepts a TOTP code, or if allowRecovery an unused
+// recovery code, which is then used up
+func (s *authService) checkSecondFactor(ctx context.Context, userID uuid.UUID, code, recoveryCode string, allowRecovery bool) error {
+	if code == "" {
+		if !allowRecovery || recoveryCode == "" {
+			return ErrInvalidTwoFactorCode
+		}
+		hash := utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode))
+		used, err := s.twoFactorRepo.UseRecoveryCode(ctx, userID, hash)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	totp, err := s.twoFactorRepo.GetTOTP(ctx, userID)
+	if errors.Is(err, repositories.ErrTOTPNotFound) {
+		return ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return err
+	}
+	if totp.ConfirmedAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	step, ok := utils.ValidateTOTP(totp.Secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	used, err := s.twoFactorRepo.UseTOTPStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns new recovery codes and the hashes to store
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
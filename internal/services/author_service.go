@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+)
+
+var ErrAuthorNotFound = errors.New("author not found")
+
+// AuthorService serves the public profiles of users who have published posts
+type AuthorService interface {
+	List(ctx context.Context, page, pageSize int) (*models.PaginatedAuthorResponse, error)
+	GetByUsername(ctx context.Context, username string, postLimit int) (*models.AuthorProfileResponse, error)
+}
+
+type authorService struct {
+	userRepo    repositories.UserRepository
+	postService PostService
+}
+
+// NewAuthorService creates a new instance of AuthorService
+func NewAuthorService(userRepo repositories.UserRepository, postService PostService) AuthorService {
+	return &authorService{
+		userRepo:    userRepo,
+		postService: postService,
+	}
+}
+
+// List returns a page of authors ordered by name
+func (s *authorService) List(ctx context.Context, page, pageSize int) (*models.PaginatedAuthorResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	offset := (page - 1) * pageSize
+
+	users, total, err := s.userRepo.ListAuthors(ctx, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	postCounts, err := s.userRepo.CountPosts(ctx, ids, models.StatusPublished)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]*models.AuthorResponse, len(users))
+	for i, user := range users {
+		data[i] = authorResponse(user, postCounts[user.ID])
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	return &models.PaginatedAuthorResponse{
+		Data:       data,
+		Total:      int64(total),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetByUsername returns an author with up to postLimit of their latest
+// published posts. Inactive users and users who have not published anything
+// are not authors.
+func (s *authorService) GetByUsername(ctx context.Context, username string, postLimit int) (*models.AuthorProfileResponse, error) {
+	user, err := s.userRepo.FindByUsername(ctx, username)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, ErrAuthorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrAuthorNotFound
+	}
+
+	posts, err := s.postService.GetAll(ctx, &models.PostFilter{
+		Status:   models.StatusPublished,
+		AuthorID: &user.ID,
+		SortBy:   "published_at",
+		SortDesc: true,
+		// The author is the one being shown, and its user record is private
+		Include: &models.PostIncludes{Category: true, Tags: true},
+	}, 1, postLimit)
+	if err != nil {
+		return nil, err
+	}
+	if posts.Total == 0 {
+		return nil, ErrAuthorNotFound
+	}
+
+	return &models.AuthorProfileResponse{
+		AuthorResponse: *authorResponse(user, int64(posts.Total)),
+		LatestPosts:    posts.Posts,
+	}, nil
+}
+
+func authorResponse(user *models.User, postCount int64) *models.AuthorResponse {
+	links := user.SocialLinks
+	if links == nil {
+		links = models.SocialLinks{}
+	}
+	return &models.AuthorResponse{
+		Username:    user.Username,
+		Fullname:    user.Fullname,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+		SocialLinks: links,
+		PostCount:   postCount,
+	}
+}
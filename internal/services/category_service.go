@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/kyomel/blog-management/internal/models"
 	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/utils"
 )
 
 var (
@@ -20,17 +21,20 @@ type CategoryService interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.CategoryResponse, error)
 	GetBySlug(ctx context.Context, slug string) (*models.CategoryResponse, error)
 	GetAll(ctx context.Context, page, pageSize int) (*models.PaginatedCategoryResponse, error)
+	GetPage(ctx context.Context, cursor string, pageSize int, withTotal bool) (*models.CursorCategoryResponse, error)
 	Update(ctx context.Context, id uuid.UUID, req *models.UpdateCategoryRequest) (*models.CategoryResponse, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type categoryService struct {
-	repo *repositories.CategoryRepository
+	repo            *repositories.CategoryRepository
+	slugHistoryRepo *repositories.SlugHistoryRepository
 }
 
-func NewCategoryService(repo *repositories.CategoryRepository) CategoryService {
+func NewCategoryService(repo *repositories.CategoryRepository, slugHistoryRepo *repositories.SlugHistoryRepository) CategoryService {
 	return &categoryService{
-		repo: repo,
+		repo:            repo,
+		slugHistoryRepo: slugHistoryRepo,
 	}
 }
 
@@ -43,12 +47,20 @@ func (s *categoryService) Create(ctx context.Context, req *models.CreateCategory
 		return nil, ErrCategoryNameConflict
 	}
 
-	other, err = s.repo.GetBySlug(req.Slug)
-	if err != nil {
-		return nil, err
-	}
-	if other != nil {
-		return nil, ErrCategorySlugConflict
+	if req.Slug == "" {
+		slug, err := generateSlug(req.Name, "category", s.slugTaken)
+		if err != nil {
+			return nil, err
+		}
+		req.Slug = slug
+	} else {
+		other, err = s.repo.GetBySlug(req.Slug)
+		if err != nil {
+			return nil, err
+		}
+		if other != nil {
+			return nil, ErrCategorySlugConflict
+		}
 	}
 
 	category := &models.Category{
@@ -75,13 +87,29 @@ func (s *categoryService) GetByID(ctx context.Context, id uuid.UUID) (*models.Ca
 	return category.ToResponse(), nil
 }
 
+// GetBySlug also resolves slugs the category used before it was renamed, in which
+// case the returned Slug differs from the one requested
 func (s *categoryService) GetBySlug(ctx context.Context, slug string) (*models.CategoryResponse, error) {
 	category, err := s.repo.GetBySlug(slug)
 	if err != nil {
 		return nil, err
 	}
 	if category == nil {
-		return nil, ErrCategoryNotFound
+		id, err := s.slugHistoryRepo.FindEntityID(models.SlugEntityCategory, slug)
+		if err != nil {
+			return nil, err
+		}
+		if id == nil {
+			return nil, ErrCategoryNotFound
+		}
+
+		category, err = s.repo.GetByID(*id)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, ErrCategoryNotFound
+		}
 	}
 	return category.ToResponse(), nil
 }
@@ -119,6 +147,48 @@ func (s *categoryService) GetAll(ctx context.Context, page, pageSize int) (*mode
 	}, nil
 }
 
+// GetPage retrieves categories in name order with keyset pagination
+func (s *categoryService) GetPage(ctx context.Context, cursor string, pageSize int, withTotal bool) (*models.CursorCategoryResponse, error) {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	after, err := decodeCursor(cursor, repositories.NameSort, false)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repo.GetPage(after, pageSize+1)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return nil, ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CursorCategoryResponse{
+		Data:     make([]*models.CategoryResponse, 0, len(categories)),
+		PageSize: pageSize,
+	}
+	if len(categories) > pageSize {
+		categories = categories[:pageSize]
+		result.NextCursor = utils.EncodeCursor(repositories.CategoryCursor(categories[len(categories)-1]))
+	}
+	for _, c := range categories {
+		result.Data = append(result.Data, c.ToResponse())
+	}
+
+	if withTotal {
+		total, err := s.repo.Count()
+		if err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+
+	return result, nil
+}
+
 func (s *categoryService) Update(ctx context.Context, id uuid.UUID, req *models.UpdateCategoryRequest) (*models.CategoryResponse, error) {
 	existing, err := s.repo.GetByID(id)
 	if err != nil {
@@ -161,6 +231,15 @@ func (s *categoryService) Update(ctx context.Context, id uuid.UUID, req *models.
 	return existing.ToResponse(), nil
 }
 
+// slugTaken reports whether a live category already uses the slug
+func (s *categoryService) slugTaken(slug string) (bool, error) {
+	category, err := s.repo.GetBySlug(slug)
+	if err != nil {
+		return false, err
+	}
+	return category != nil, nil
+}
+
 func (s *categoryService) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := s.repo.GetByID(id)
 	if err != nil {
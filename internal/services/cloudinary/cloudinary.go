@@ -3,7 +3,12 @@ package cloudinary
 import (
 	"context"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
@@ -12,6 +17,7 @@ import (
 
 type CloudinaryService struct {
 	cloudinary *cloudinary.Cloudinary
+	cloudName  string
 	folder     string
 }
 
@@ -23,11 +29,18 @@ func NewCloudinaryService(cloudName, apiKey, apiSecret, folder string) (*Cloudin
 
 	return &CloudinaryService{
 		cloudinary: cld,
+		cloudName:  cloudName,
 		folder:     folder,
 	}, nil
 }
 
 func (s *CloudinaryService) UploadImage(ctx context.Context, file multipart.File, filename string) (string, error) {
+	url, _, err := s.UploadFromReader(ctx, file, filename)
+	return url, err
+}
+
+// UploadFromReader uploads image data read from r and returns its URL and public ID
+func (s *CloudinaryService) UploadFromReader(ctx context.Context, r io.Reader, filename string) (string, string, error) {
 	uploadParams := uploader.UploadParams{
 		PublicID:     filename,
 		Folder:       s.folder,
@@ -35,15 +48,73 @@ func (s *CloudinaryService) UploadImage(ctx context.Context, file multipart.File
 		Timestamp:    time.Now().Unix(),
 	}
 
-	result, err := s.cloudinary.Upload.Upload(ctx, file, uploadParams)
+	result, err := s.cloudinary.Upload.Upload(ctx, r, uploadParams)
 	if err != nil {
-		return "", fmt.Errorf("failed to upload image: %w", err)
+		return "", "", fmt.Errorf("failed to upload image: %w", err)
 	}
 
-	return result.SecureURL, nil
+	return result.SecureURL, result.PublicID, nil
 }
 
 func (s *CloudinaryService) UploadAvatar(ctx context.Context, file multipart.File, userID string) (string, error) {
 	filename := fmt.Sprintf("avatar_%s_%d", userID, time.Now().Unix())
 	return s.UploadImage(ctx, file, filename)
 }
+
+// DeleteAvatar removes an avatar uploaded by UploadAvatar for the user. URLs
+// of images stored elsewhere, such as a login provider's picture, are left
+// alone.
+func (s *CloudinaryService) DeleteAvatar(ctx context.Context, avatarURL string, userID string) error {
+	publicID, ok := s.avatarPublicID(avatarURL, userID)
+	if !ok {
+		return nil
+	}
+
+	invalidate := true
+	result, err := s.cloudinary.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     publicID,
+		ResourceType: "image",
+		Invalidate:   &invalidate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete image: %w", err)
+	}
+	if result.Error.Message != "" {
+		return fmt.Errorf("failed to delete image: %s", result.Error.Message)
+	}
+	// "not found" means it is already gone, which is what we want
+	if result.Result != "ok" && result.Result != "not found" {
+		return fmt.Errorf("failed to delete image: %s", result.Result)
+	}
+	return nil
+}
+
+// avatarPublicID extracts the public ID from a delivery URL such as
+// https://res.cloudinary.com/<cloud>/image/upload/v123/<folder>/avatar_<user>_<time>.jpg.
+// Only the user's own avatars in our folder are recognised.
+func (s *CloudinaryService) avatarPublicID(avatarURL string, userID string) (string, bool) {
+	u, err := url.Parse(avatarURL)
+	if err != nil || u.Host != "res.cloudinary.com" {
+		return "", false
+	}
+
+	publicID, ok := strings.CutPrefix(u.Path, "/"+s.cloudName+"/image/upload/")
+	if !ok {
+		return "", false
+	}
+	if version, rest, found := strings.Cut(publicID, "/"); found && len(version) > 1 && version[0] == 'v' {
+		if _, err := strconv.ParseInt(version[1:], 10, 64); err == nil {
+			publicID = rest
+		}
+	}
+	publicID = strings.TrimSuffix(publicID, path.Ext(publicID))
+
+	prefix := "avatar_" + userID + "_"
+	if s.folder != "" {
+		prefix = s.folder + "/" + prefix
+	}
+	if !strings.HasPrefix(publicID, prefix) {
+		return "", false
+	}
+	return publicID, true
+}
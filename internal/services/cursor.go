@@ -0,0 +1,23 @@
+package services
+
+import (
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/utils"
+)
+
+// ErrInvalidCursor is the error utils.DecodeCursor fails with, so every layer
+// reports a bad page token with the same sentinel
+var ErrInvalidCursor = utils.ErrInvalidCursor
+
+// decodeCursor parses a page token and checks that it was issued for the
+// same ordering. An empty token yields a nil cursor for the first page.
+func decodeCursor(token, sort string, desc bool) (*models.Cursor, error) {
+	cursor, err := utils.DecodeCursor(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor != nil && (cursor.Sort != sort || cursor.Desc != desc) {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
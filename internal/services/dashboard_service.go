@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+)
+
+const dashboardAudits = 20
+
+// DashboardConfig sets the periods the dashboard figures cover
+type DashboardConfig struct {
+	// StaleDraftDays is how long a draft goes unchanged before it is stale
+	StaleDraftDays int
+	// SignupDays is how far back signups count as recent
+	SignupDays int
+}
+
+// DashboardService provides the admin dashboard figures
+type DashboardService interface {
+	GetStats(ctx context.Context) (*models.DashboardStats, error)
+}
+
+type dashboardService struct {
+	repo     *repositories.DashboardRepository
+	config   DashboardConfig
+	cacheTTL time.Duration
+
+	mu     sync.Mutex
+	cached *models.DashboardStats
+}
+
+// NewDashboardService creates a dashboard service that reuses computed stats
+// for cacheTTL
+func NewDashboardService(repo *repositories.DashboardRepository, config DashboardConfig, cacheTTL time.Duration) DashboardService {
+	return &dashboardService{
+		repo:     repo,
+		config:   config,
+		cacheTTL: cacheTTL,
+	}
+}
+
+func (s *dashboardService) GetStats(ctx context.Context) (*models.DashboardStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.cached != nil && now.Sub(s.cached.GeneratedAt) < s.cacheTTL {
+		return s.cached, nil
+	}
+
+	stats, err := s.repo.GetStats(
+		ctx,
+		now,
+		now.AddDate(0, 0, -s.config.StaleDraftDays),
+		now.AddDate(0, 0, -s.config.SignupDays),
+		dashboardAudits,
+	)
+	if err != nil {
+		return nil, err
+	}
+	stats.StaleDraftDays = s.config.StaleDraftDays
+	stats.SignupDays = s.config.SignupDays
+	stats.GeneratedAt = now
+
+	s.cached = stats
+	return stats, nil
+}
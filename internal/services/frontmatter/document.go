@@ -0,0 +1,89 @@
+package frontmatter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kyomel/blog-management/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrMissingFrontMatter = errors.New("file does not start with a front matter block")
+)
+
+const delimiter = "---"
+
+// FrontMatter is the YAML header of an exported post. Category, tags and
+// author are referenced by slug and username so files stay readable and
+// portable between databases.
+type FrontMatter struct {
+	Title         string                 `yaml:"title"`
+	Slug          string                 `yaml:"slug"`
+	Status        models.PostStatus      `yaml:"status"`
+	Format        models.ContentFormat   `yaml:"format,omitempty"`
+	Category      string                 `yaml:"category"`
+	Tags          []string               `yaml:"tags,omitempty"`
+	Author        string                 `yaml:"author,omitempty"`
+	Excerpt       string                 `yaml:"excerpt,omitempty"`
+	FeaturedImage string                 `yaml:"featured_image,omitempty"`
+	Featured      bool                   `yaml:"featured"`
+	PublishedAt   *time.Time             `yaml:"published_at,omitempty"`
+	CreatedAt     *time.Time             `yaml:"created_at,omitempty"`
+	UpdatedAt     *time.Time             `yaml:"updated_at,omitempty"`
+	Metadata      map[string]interface{} `yaml:"metadata,omitempty"`
+}
+
+// Encode writes the front matter followed by the post body
+func Encode(fm *FrontMatter, body string) ([]byte, error) {
+	header, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(delimiter + "\n")
+	buf.Write(header)
+	buf.WriteString(delimiter + "\n\n")
+	buf.WriteString(strings.TrimRight(body, "\n"))
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// Decode splits a Markdown file into its front matter and body
+func Decode(data []byte) (*FrontMatter, string, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.TrimPrefix(text, "\ufeff")
+
+	if !strings.HasPrefix(text, delimiter+"\n") {
+		return nil, "", ErrMissingFrontMatter
+	}
+	rest := text[len(delimiter)+1:]
+
+	var header, body string
+	if strings.HasPrefix(rest, delimiter+"\n") {
+		body = rest[len(delimiter)+1:]
+	} else {
+		end := strings.Index(rest, "\n"+delimiter+"\n")
+		if end < 0 {
+			if !strings.HasSuffix(rest, "\n"+delimiter) {
+				return nil, "", ErrMissingFrontMatter
+			}
+			end = len(rest) - len(delimiter) - 1
+			header = rest[:end]
+		} else {
+			header = rest[:end]
+			body = rest[end+len(delimiter)+2:]
+		}
+	}
+
+	fm := &FrontMatter{}
+	if err := yaml.Unmarshal([]byte(header), fm); err != nil {
+		return nil, "", fmt.Errorf("invalid front matter: %w", err)
+	}
+
+	return fm, strings.TrimLeft(body, "\n"), nil
+}
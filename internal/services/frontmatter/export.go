@@ -0,0 +1,113 @@
+package frontmatter
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+const exportPageSize = 100
+
+// ExportZip writes every post as <slug>.md into a zip archive
+func (s *Service) ExportZip(ctx context.Context, w io.Writer) (int, error) {
+	archive := zip.NewWriter(w)
+
+	count, err := s.export(ctx, func(name string, data []byte) error {
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, archive.Close()
+}
+
+// ExportDir writes every post as <slug>.md into dir, overwriting existing files
+func (s *Service) ExportDir(ctx context.Context, dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+
+	return s.export(ctx, func(name string, data []byte) error {
+		return os.WriteFile(filepath.Join(dir, name), data, 0o644)
+	})
+}
+
+func (s *Service) export(ctx context.Context, write func(name string, data []byte) error) (int, error) {
+	count := 0
+	for page := 1; ; page++ {
+		result, err := s.postService.GetAll(ctx, &models.PostFilter{SortBy: "created_at"}, page, exportPageSize)
+		if err != nil {
+			return count, err
+		}
+
+		for _, summary := range result.Posts {
+			// Listings omit the content, so load each post in full
+			post, err := s.postService.GetByID(ctx, summary.ID)
+			if err != nil {
+				return count, err
+			}
+			if post == nil {
+				continue
+			}
+
+			data, err := encodePost(post)
+			if err != nil {
+				return count, err
+			}
+			if err := write(post.Slug+".md", data); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if page >= result.TotalPages {
+			return count, nil
+		}
+	}
+}
+
+func encodePost(post *models.PostResponse) ([]byte, error) {
+	fm := &FrontMatter{
+		Title:         post.Title,
+		Slug:          post.Slug,
+		Status:        post.Status,
+		Format:        post.ContentFormat,
+		Excerpt:       post.Excerpt,
+		FeaturedImage: post.FeaturedImageURL,
+		Featured:      post.IsFeatured,
+		PublishedAt:   post.PublishedAt,
+		CreatedAt:     post.CreatedAt,
+		UpdatedAt:     post.UpdatedAt,
+	}
+	if post.Category != nil {
+		fm.Category = post.Category.Slug
+	}
+	if post.Author != nil {
+		fm.Author = post.Author.Username
+	}
+	for _, tag := range post.Tags {
+		fm.Tags = append(fm.Tags, tag.Slug)
+	}
+
+	// Metadata is arbitrary JSON; only objects map onto the YAML header
+	if post.Metadata != nil {
+		raw, err := json.Marshal(post.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal(raw, &fm.Metadata)
+	}
+
+	return Encode(fm, post.Content)
+}
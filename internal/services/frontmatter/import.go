@@ -0,0 +1,222 @@
+package frontmatter
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+type FileError struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// ImportReport summarises a Markdown import
+type ImportReport struct {
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Failed  []FileError `json:"failed"`
+}
+
+// ImportZip imports every .md file in a zip archive
+func (s *Service) ImportZip(ctx context.Context, r io.ReaderAt, size int64, defaultAuthorID uuid.UUID) (*ImportReport, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip archive: %w", err)
+	}
+	return s.ImportFS(ctx, archive, defaultAuthorID)
+}
+
+// ImportDir imports every .md file below dir
+func (s *Service) ImportDir(ctx context.Context, dir string, defaultAuthorID uuid.UUID) (*ImportReport, error) {
+	return s.ImportFS(ctx, os.DirFS(dir), defaultAuthorID)
+}
+
+// ImportFS creates or updates a post for every .md file in fsys. Files that
+// cannot be imported are listed in the report and do not stop the import.
+// defaultAuthorID owns new posts whose author is not a known username.
+func (s *Service) ImportFS(ctx context.Context, fsys fs.FS, defaultAuthorID uuid.UUID) (*ImportReport, error) {
+	report := &ImportReport{Failed: []FileError{}}
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(path.Ext(name), ".md") {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		created, err := s.importFile(ctx, name, data, defaultAuthorID)
+		switch {
+		case err != nil:
+			report.Failed = append(report.Failed, FileError{File: name, Error: err.Error()})
+		case created:
+			report.Created++
+		default:
+			report.Updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// importFile saves one file and reports whether a new post was created
+func (s *Service) importFile(ctx context.Context, name string, data []byte, defaultAuthorID uuid.UUID) (bool, error) {
+	fm, body, err := Decode(data)
+	if err != nil {
+		return false, err
+	}
+
+	if fm.Slug == "" {
+		fm.Slug = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+	if fm.Title == "" {
+		return false, errors.New("title is required")
+	}
+	if strings.TrimSpace(body) == "" {
+		return false, errors.New("content is required")
+	}
+	if fm.Format == "" {
+		fm.Format = models.FormatMarkdown
+	}
+
+	var metadata []byte
+	if fm.Metadata != nil {
+		metadata, err = json.Marshal(fm.Metadata)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	tagIDs, err := s.resolveTags(ctx, fm.Tags)
+	if err != nil {
+		return false, err
+	}
+
+	var categoryID uuid.UUID
+	if fm.Category != "" {
+		category, err := s.categoryService.GetBySlug(ctx, fm.Category)
+		if errors.Is(err, services.ErrCategoryNotFound) || (err == nil && category == nil) {
+			return false, fmt.Errorf("category %q does not exist", fm.Category)
+		}
+		if err != nil {
+			return false, err
+		}
+		categoryID = category.ID
+	}
+
+	existing, err := s.postService.GetBySlug(ctx, fm.Slug)
+	if err != nil && !errors.Is(err, services.ErrPostNotFound) {
+		return false, err
+	}
+
+	// A renamed post still answers to its old slug; only an exact match is updated
+	if existing != nil && existing.Slug == fm.Slug {
+		featured := fm.Featured
+		_, err := s.postService.Update(ctx, existing.ID, &models.UpdatePostRequest{
+			CategoryID:       categoryID,
+			Title:            fm.Title,
+			Content:          body,
+			ContentFormat:    fm.Format,
+			Excerpt:          fm.Excerpt,
+			FeaturedImageURL: fm.FeaturedImage,
+			Status:           fm.Status,
+			IsFeatured:       &featured,
+			Metadata:         metadata,
+			TagIDs:           tagIDs,
+			PublishedAt:      fm.PublishedAt,
+		})
+		return false, err
+	}
+
+	if categoryID == uuid.Nil {
+		return false, errors.New("category is required for new posts")
+	}
+
+	authorID, err := s.resolveAuthor(ctx, fm.Author, defaultAuthorID)
+	if err != nil {
+		return false, err
+	}
+
+	status := fm.Status
+	if status == "" {
+		status = models.StatusDraft
+	}
+
+	_, err = s.postService.Create(ctx, &models.CreatePostRequest{
+		AuthorID:         authorID,
+		CategoryID:       categoryID,
+		Title:            fm.Title,
+		Slug:             fm.Slug,
+		Content:          body,
+		ContentFormat:    fm.Format,
+		Excerpt:          fm.Excerpt,
+		FeaturedImageURL: fm.FeaturedImage,
+		Status:           status,
+		IsFeatured:       fm.Featured,
+		Metadata:         metadata,
+		TagIDs:           tagIDs,
+		PublishedAt:      fm.PublishedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// resolveTags maps tag slugs to IDs, creating tags that do not exist yet
+func (s *Service) resolveTags(ctx context.Context, slugs []string) ([]uuid.UUID, error) {
+	tagIDs := []uuid.UUID{}
+	for _, slug := range slugs {
+		tag, err := s.tagService.GetBySlug(ctx, slug)
+		if err != nil && !errors.Is(err, services.ErrTagNotFound) {
+			return nil, err
+		}
+		if tag == nil {
+			tag, err = s.tagService.Create(ctx, &models.CreateTagRequest{Name: slug, Slug: slug})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create tag %q: %w", slug, err)
+			}
+		}
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	return tagIDs, nil
+}
+
+func (s *Service) resolveAuthor(ctx context.Context, username string, defaultAuthorID uuid.UUID) (uuid.UUID, error) {
+	if username != "" {
+		user, err := s.userRepo.FindByUsername(ctx, username)
+		if err != nil && !errors.Is(err, repositories.ErrUserNotFound) {
+			return uuid.Nil, err
+		}
+		if user != nil {
+			return user.ID, nil
+		}
+	}
+
+	if defaultAuthorID == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("author %q does not exist and no default author was given", username)
+	}
+	return defaultAuthorID, nil
+}
@@ -0,0 +1,29 @@
+package frontmatter
+
+import (
+	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/services"
+)
+
+// Service exports posts as Markdown files with YAML front matter and imports
+// them back, matching posts by slug
+type Service struct {
+	userRepo        repositories.UserRepository
+	categoryService services.CategoryService
+	tagService      services.TagService
+	postService     services.PostService
+}
+
+func NewService(
+	userRepo repositories.UserRepository,
+	categoryService services.CategoryService,
+	tagService services.TagService,
+	postService services.PostService,
+) *Service {
+	return &Service{
+		userRepo:        userRepo,
+		categoryService: categoryService,
+		tagService:      tagService,
+		postService:     postService,
+	}
+}
@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown import format")
+)
+
+const (
+	FormatWordPress = "wordpress"
+	FormatGhost     = "ghost"
+)
+
+// Document is the content of an export file, independent of the blog engine it came from
+type Document struct {
+	Source     string
+	Authors    []Author
+	Categories []Term
+	Tags       []Term
+	Posts      []Post
+}
+
+type Author struct {
+	// Key is how posts in the export refer to the author
+	Key      string
+	Email    string
+	Username string
+	Fullname string
+}
+
+type Term struct {
+	Name        string
+	Slug        string
+	Description string
+}
+
+type Post struct {
+	SourceID         string
+	Title            string
+	Slug             string
+	Content          string
+	Excerpt          string
+	Status           models.PostStatus
+	AuthorKey        string
+	CategorySlugs    []string
+	TagSlugs         []string
+	FeaturedImageURL string
+	PublishedAt      *time.Time
+	// SkipReason is set for entries that are not imported, such as pages or trashed posts
+	SkipReason string
+}
+
+// Parse reads an export file in the given format
+func Parse(format string, r io.Reader) (*Document, error) {
+	switch format {
+	case FormatWordPress:
+		return ParseWXR(r)
+	case FormatGhost:
+		return ParseGhost(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// DetectFormat guesses the export format from a file name
+func DetectFormat(filename string) (string, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".xml":
+		return FormatWordPress, nil
+	case ".json":
+		return FormatGhost, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+type ghostData struct {
+	Posts []struct {
+		ID            string     `json:"id"`
+		Title         string     `json:"title"`
+		Slug          string     `json:"slug"`
+		HTML          string     `json:"html"`
+		CustomExcerpt string     `json:"custom_excerpt"`
+		FeatureImage  string     `json:"feature_image"`
+		Status        string     `json:"status"`
+		Type          string     `json:"type"`
+		Page          bool       `json:"page"`
+		AuthorID      string     `json:"author_id"`
+		PublishedAt   *ghostTime `json:"published_at"`
+	} `json:"posts"`
+	Tags []struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Slug        string `json:"slug"`
+		Description string `json:"description"`
+	} `json:"tags"`
+	Users []struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Slug  string `json:"slug"`
+		Email string `json:"email"`
+	} `json:"users"`
+	PostsTags []struct {
+		PostID string `json:"post_id"`
+		TagID  string `json:"tag_id"`
+	} `json:"posts_tags"`
+	PostsAuthors []struct {
+		PostID    string `json:"post_id"`
+		AuthorID  string `json:"author_id"`
+		SortOrder int    `json:"sort_order"`
+	} `json:"posts_authors"`
+}
+
+// ghostTime accepts both the RFC 3339 strings of current exports and the
+// millisecond timestamps of older ones
+type ghostTime time.Time
+
+func (t *ghostTime) UnmarshalJSON(data []byte) error {
+	var millis int64
+	if err := json.Unmarshal(data, &millis); err == nil {
+		*t = ghostTime(time.UnixMilli(millis).UTC())
+		return nil
+	}
+
+	var parsed time.Time
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	*t = ghostTime(parsed)
+	return nil
+}
+
+// Ghost exports wrap the data in {"db": [{"data": ...}]}, older ones use {"data": ...}
+type ghostExport struct {
+	DB []struct {
+		Data ghostData `json:"data"`
+	} `json:"db"`
+	Data *ghostData `json:"data"`
+}
+
+// ParseGhost reads a Ghost JSON export. Ghost has no categories, so posts are
+// imported into the importer's default category and keep their tags.
+func ParseGhost(r io.Reader) (*Document, error) {
+	var export ghostExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to parse Ghost export: %w", err)
+	}
+
+	var data ghostData
+	switch {
+	case len(export.DB) > 0:
+		data = export.DB[0].Data
+	case export.Data != nil:
+		data = *export.Data
+	default:
+		return nil, fmt.Errorf("failed to parse Ghost export: no data found")
+	}
+
+	doc := &Document{Source: FormatGhost}
+
+	for _, u := range data.Users {
+		doc.Authors = append(doc.Authors, Author{
+			Key:      u.ID,
+			Email:    u.Email,
+			Username: u.Slug,
+			Fullname: u.Name,
+		})
+	}
+
+	tagSlugs := map[string]string{}
+	for _, t := range data.Tags {
+		tagSlugs[t.ID] = t.Slug
+		doc.Tags = append(doc.Tags, Term{Name: t.Name, Slug: t.Slug, Description: t.Description})
+	}
+
+	postTags := map[string][]string{}
+	for _, pt := range data.PostsTags {
+		if slug, ok := tagSlugs[pt.TagID]; ok {
+			postTags[pt.PostID] = append(postTags[pt.PostID], slug)
+		}
+	}
+
+	// The primary author has the lowest sort order
+	postAuthors := map[string]string{}
+	authorOrder := map[string]int{}
+	for _, pa := range data.PostsAuthors {
+		if order, ok := authorOrder[pa.PostID]; !ok || pa.SortOrder < order {
+			postAuthors[pa.PostID] = pa.AuthorID
+			authorOrder[pa.PostID] = pa.SortOrder
+		}
+	}
+
+	for _, p := range data.Posts {
+		post := Post{
+			SourceID:         p.ID,
+			Title:            p.Title,
+			Slug:             p.Slug,
+			Content:          p.HTML,
+			Excerpt:          p.CustomExcerpt,
+			AuthorKey:        p.AuthorID,
+			TagSlugs:         postTags[p.ID],
+			FeaturedImageURL: p.FeatureImage,
+		}
+		if p.PublishedAt != nil {
+			published := time.Time(*p.PublishedAt)
+			post.PublishedAt = &published
+		}
+		if author, ok := postAuthors[p.ID]; ok {
+			post.AuthorKey = author
+		}
+
+		switch p.Status {
+		case "published":
+			post.Status = models.StatusPublished
+		case "draft", "scheduled":
+			post.Status = models.StatusDraft
+		default:
+			post.SkipReason = fmt.Sprintf("unsupported status %q", p.Status)
+		}
+
+		if p.Page || (p.Type != "" && p.Type != "post") {
+			post.SkipReason = "pages are not imported"
+		}
+
+		doc.Posts = append(doc.Posts, post)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,391 @@
+package importer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/services"
+	"github.com/kyomel/blog-management/internal/utils"
+)
+
+const defaultCategoryName = "Uncategorized"
+
+// MediaStore uploads image data and returns its public URL and storage ID
+type MediaStore interface {
+	UploadFromReader(ctx context.Context, r io.Reader, filename string) (string, string, error)
+}
+
+type Options struct {
+	// DefaultAuthorID owns posts whose author is missing or conflicts with an existing account
+	DefaultAuthorID uuid.UUID
+	// DefaultCategory is the name of the category for posts that have none
+	DefaultCategory string
+	// BaseURL resolves relative image URLs, including Ghost's __GHOST_URL__ placeholder
+	BaseURL   string
+	SkipMedia bool
+}
+
+type Counts struct {
+	Created  int `json:"created"`
+	Existing int `json:"existing"`
+}
+
+type Issue struct {
+	Type   string `json:"type"`
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+// Report summarises an import run
+type Report struct {
+	Source     string  `json:"source"`
+	Users      Counts  `json:"users"`
+	Categories Counts  `json:"categories"`
+	Tags       Counts  `json:"tags"`
+	Posts      Counts  `json:"posts"`
+	Media      Counts  `json:"media"`
+	Skipped    []Issue `json:"skipped"`
+	Conflicts  []Issue `json:"conflicts"`
+}
+
+func (r *Report) skip(kind, key, reason string) {
+	r.Skipped = append(r.Skipped, Issue{Type: kind, Key: key, Reason: reason})
+}
+
+func (r *Report) conflict(kind, key, reason string) {
+	r.Conflicts = append(r.Conflicts, Issue{Type: kind, Key: key, Reason: reason})
+}
+
+// Importer writes parsed export documents into the blog. Everything is matched
+// by email or slug first, so running the same import twice creates nothing new.
+type Importer struct {
+	userRepo        repositories.UserRepository
+	mediaRepo       *repositories.MediaRepository
+	categoryService services.CategoryService
+	tagService      services.TagService
+	postService     services.PostService
+	mediaStore      MediaStore
+	httpClient      *http.Client
+}
+
+func NewImporter(
+	userRepo repositories.UserRepository,
+	mediaRepo *repositories.MediaRepository,
+	categoryService services.CategoryService,
+	tagService services.TagService,
+	postService services.PostService,
+	mediaStore MediaStore,
+) *Importer {
+	return &Importer{
+		userRepo:        userRepo,
+		mediaRepo:       mediaRepo,
+		categoryService: categoryService,
+		tagService:      tagService,
+		postService:     postService,
+		mediaStore:      mediaStore,
+		httpClient:      newImageClient(),
+	}
+}
+
+// importRun holds the ID mappings built while importing one document
+type importRun struct {
+	opts       Options
+	report     *Report
+	authors    map[string]uuid.UUID
+	categories map[string]uuid.UUID
+	tags       map[string]uuid.UUID
+	media      map[string]string
+}
+
+func (i *Importer) Import(ctx context.Context, doc *Document, opts Options) (*Report, error) {
+	if opts.DefaultAuthorID == uuid.Nil {
+		return nil, errors.New("a default author is required")
+	}
+	if opts.DefaultCategory == "" {
+		opts.DefaultCategory = defaultCategoryName
+	}
+
+	run := &importRun{
+		opts:       opts,
+		report:     &Report{Source: doc.Source, Skipped: []Issue{}, Conflicts: []Issue{}},
+		authors:    map[string]uuid.UUID{},
+		categories: map[string]uuid.UUID{},
+		tags:       map[string]uuid.UUID{},
+		media:      map[string]string{},
+	}
+
+	for _, author := range doc.Authors {
+		if err := i.importAuthor(ctx, run, author); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, term := range doc.Categories {
+		if err := i.importCategory(ctx, run, term); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, term := range doc.Tags {
+		if err := i.importTag(ctx, run, term); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, post := range doc.Posts {
+		if err := i.importPost(ctx, run, post); err != nil {
+			return nil, err
+		}
+	}
+
+	return run.report, nil
+}
+
+func (i *Importer) importAuthor(ctx context.Context, run *importRun, author Author) error {
+	if author.Email == "" {
+		run.report.skip("user", author.Key, "no email address")
+		return nil
+	}
+
+	existing, err := i.userRepo.FindByEmail(ctx, author.Email)
+	if err != nil && !errors.Is(err, repositories.ErrUserNotFound) {
+		return err
+	}
+	if existing != nil {
+		run.authors[author.Key] = existing.ID
+		run.report.Users.Existing++
+		return nil
+	}
+
+	username := author.Username
+	if username == "" {
+		username = utils.Slugify(author.Fullname)
+	}
+
+	password, err := randomPassword()
+	if err != nil {
+		return err
+	}
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	// Imported accounts stay inactive until an admin enables them
+	user := &models.User{
+		Email:        author.Email,
+		Username:     username,
+		Fullname:     author.Fullname,
+		PasswordHash: passwordHash,
+		Role:         models.RoleUser,
+		IsActive:     false,
+	}
+
+	err = i.userRepo.Create(ctx, user)
+	if errors.Is(err, repositories.ErrUsernameAlreadyExists) {
+		run.report.conflict("user", author.Key, fmt.Sprintf("username %q belongs to another account", username))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	run.authors[author.Key] = user.ID
+	run.report.Users.Created++
+	return nil
+}
+
+func (i *Importer) importCategory(ctx context.Context, run *importRun, term Term) error {
+	existing, err := i.categoryService.GetBySlug(ctx, term.Slug)
+	if err != nil && !errors.Is(err, services.ErrCategoryNotFound) {
+		return err
+	}
+	if existing != nil {
+		run.categories[term.Slug] = existing.ID
+		run.report.Categories.Existing++
+		return nil
+	}
+
+	created, err := i.categoryService.Create(ctx, &models.CreateCategoryRequest{
+		Name:        term.Name,
+		Slug:        term.Slug,
+		Description: term.Description,
+	})
+	if errors.Is(err, services.ErrCategoryNameConflict) || errors.Is(err, services.ErrCategorySlugConflict) {
+		run.report.conflict("category", term.Slug, err.Error())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	run.categories[term.Slug] = created.ID
+	run.report.Categories.Created++
+	return nil
+}
+
+func (i *Importer) importTag(ctx context.Context, run *importRun, term Term) error {
+	existing, err := i.tagService.GetBySlug(ctx, term.Slug)
+	if err != nil && !errors.Is(err, services.ErrTagNotFound) {
+		return err
+	}
+	if existing != nil {
+		run.tags[term.Slug] = existing.ID
+		run.report.Tags.Existing++
+		return nil
+	}
+
+	created, err := i.tagService.Create(ctx, &models.CreateTagRequest{
+		Name: term.Name,
+		Slug: term.Slug,
+	})
+	if errors.Is(err, services.ErrTagNameConflict) || errors.Is(err, services.ErrTagSlugConflict) {
+		run.report.conflict("tag", term.Slug, err.Error())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	run.tags[term.Slug] = created.ID
+	run.report.Tags.Created++
+	return nil
+}
+
+// defaultCategory returns the category for posts without one, creating it on first use
+func (i *Importer) defaultCategory(ctx context.Context, run *importRun) (uuid.UUID, error) {
+	slug := utils.Slugify(run.opts.DefaultCategory)
+	if id, ok := run.categories[slug]; ok {
+		return id, nil
+	}
+
+	if err := i.importCategory(ctx, run, Term{Name: run.opts.DefaultCategory, Slug: slug}); err != nil {
+		return uuid.Nil, err
+	}
+
+	id, ok := run.categories[slug]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("default category %q could not be created", run.opts.DefaultCategory)
+	}
+	return id, nil
+}
+
+func (i *Importer) importPost(ctx context.Context, run *importRun, post Post) error {
+	key := post.Slug
+	if key == "" {
+		key = post.SourceID
+	}
+
+	if post.SkipReason != "" {
+		run.report.skip("post", key, post.SkipReason)
+		return nil
+	}
+
+	slug := post.Slug
+	if slug == "" {
+		slug = utils.Slugify(post.Title)
+	}
+	if slug == "" {
+		run.report.skip("post", key, "no slug or title")
+		return nil
+	}
+
+	existing, err := i.postService.GetBySlug(ctx, slug)
+	if err != nil && !errors.Is(err, services.ErrPostNotFound) {
+		return err
+	}
+	if existing != nil {
+		run.report.Posts.Existing++
+		return nil
+	}
+
+	authorID, ok := run.authors[post.AuthorKey]
+	if !ok {
+		authorID = run.opts.DefaultAuthorID
+	}
+
+	var categoryID uuid.UUID
+	for _, categorySlug := range post.CategorySlugs {
+		if id, ok := run.categories[categorySlug]; ok {
+			categoryID = id
+			break
+		}
+	}
+	if categoryID == uuid.Nil {
+		categoryID, err = i.defaultCategory(ctx, run)
+		if err != nil {
+			return err
+		}
+	}
+
+	var tagIDs []uuid.UUID
+	for _, tagSlug := range post.TagSlugs {
+		if id, ok := run.tags[tagSlug]; ok {
+			tagIDs = append(tagIDs, id)
+		}
+	}
+
+	content := post.Content
+	featuredImageURL := post.FeaturedImageURL
+	if run.opts.BaseURL != "" {
+		siteURL := strings.TrimSuffix(run.opts.BaseURL, "/")
+		content = strings.ReplaceAll(content, ghostURLPlaceholder, siteURL)
+		featuredImageURL = strings.ReplaceAll(featuredImageURL, ghostURLPlaceholder, siteURL)
+	}
+	if !run.opts.SkipMedia {
+		content = i.rehostContentImages(ctx, run, content, authorID)
+		if featuredImageURL != "" {
+			featuredImageURL = i.rehostImage(ctx, run, featuredImageURL, authorID)
+		}
+	}
+
+	metadata, err := json.Marshal(map[string]interface{}{
+		"import": map[string]string{
+			"source": run.report.Source,
+			"id":     post.SourceID,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = i.postService.Create(ctx, &models.CreatePostRequest{
+		AuthorID:         authorID,
+		CategoryID:       categoryID,
+		Title:            post.Title,
+		Slug:             slug,
+		Content:          content,
+		ContentFormat:    models.FormatHTML,
+		Excerpt:          post.Excerpt,
+		FeaturedImageURL: featuredImageURL,
+		Status:           post.Status,
+		Metadata:         metadata,
+		TagIDs:           tagIDs,
+		PublishedAt:      post.PublishedAt,
+	})
+	if err != nil {
+		run.report.skip("post", key, err.Error())
+		return nil
+	}
+
+	run.report.Posts.Created++
+	return nil
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,243 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+	"golang.org/x/net/html"
+)
+
+const (
+	ghostURLPlaceholder = "__GHOST_URL__"
+	maxImageSize        = 20 << 20 // 20 MB
+	imageTimeout        = 30 * time.Second
+)
+
+var errInternalAddress = errors.New("refusing to download from an internal address")
+
+// newImageClient returns the client images are downloaded with. Image URLs
+// come from the uploaded export, so it only connects to public addresses;
+// the check runs on the resolved IP of every connection, redirects included.
+func newImageClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: imageTimeout,
+		Control: rejectInternalAddress,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be the only address the dialer sees
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   imageTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return checkImageScheme(req.URL)
+		},
+	}
+}
+
+// rejectInternalAddress is a net.Dialer Control function that refuses
+// loopback, private, link-local and unspecified addresses
+func rejectInternalAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errInternalAddress, address)
+	}
+
+	ip := addrPort.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", errInternalAddress, ip)
+	}
+	return nil
+}
+
+func checkImageScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	return nil
+}
+
+// resolveURL makes a URL from the export absolute using Options.BaseURL
+func resolveURL(raw, baseURL string) (string, error) {
+	if strings.HasPrefix(raw, ghostURLPlaceholder) {
+		if baseURL == "" {
+			return "", fmt.Errorf("%s needs a base URL", ghostURLPlaceholder)
+		}
+		raw = strings.TrimSuffix(baseURL, "/") + strings.TrimPrefix(raw, ghostURLPlaceholder)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if u.IsAbs() {
+		return u.String(), nil
+	}
+
+	if baseURL == "" {
+		return "", fmt.Errorf("relative URL needs a base URL")
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(u).String(), nil
+}
+
+// rehostContentImages replaces the src of every <img> in content with a rehosted copy
+func (i *Importer) rehostContentImages(ctx context.Context, run *importRun, content string, ownerID uuid.UUID) string {
+	var sources []string
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := tokenizer.Token()
+		if token.Data != "img" {
+			continue
+		}
+		for _, attr := range token.Attr {
+			if attr.Key == "src" && attr.Val != "" {
+				sources = append(sources, attr.Val)
+			}
+		}
+	}
+
+	for _, src := range sources {
+		rehosted := i.rehostImage(ctx, run, src, ownerID)
+		if rehosted != src {
+			// Attribute values in the raw markup may still be entity-encoded
+			content = strings.ReplaceAll(content, src, rehosted)
+			content = strings.ReplaceAll(content, html.EscapeString(src), rehosted)
+		}
+	}
+
+	return content
+}
+
+// rehostImage copies an image into media storage and returns its new URL. On
+// failure the problem is reported and the original URL is kept.
+func (i *Importer) rehostImage(ctx context.Context, run *importRun, src string, ownerID uuid.UUID) string {
+	if hosted, ok := run.media[src]; ok {
+		return hosted
+	}
+
+	hosted, err := i.uploadImage(ctx, run, src, ownerID)
+	if err != nil {
+		run.report.skip("media", src, err.Error())
+		run.media[src] = src
+		return src
+	}
+
+	run.media[src] = hosted
+	return hosted
+}
+
+func (i *Importer) uploadImage(ctx context.Context, run *importRun, src string, ownerID uuid.UUID) (string, error) {
+	sourceURL, err := resolveURL(src, run.opts.BaseURL)
+	if err != nil {
+		return "", err
+	}
+	parsed, err := url.Parse(sourceURL)
+	if err != nil {
+		return "", err
+	}
+	if err := checkImageScheme(parsed); err != nil {
+		return "", err
+	}
+
+	existing, err := i.mediaRepo.FindBySourceURL(sourceURL)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		run.report.Media.Existing++
+		return existing.FilePath, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := i.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("not an image: %q", mimeType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxImageSize {
+		return "", fmt.Errorf("image exceeds %d bytes", maxImageSize)
+	}
+
+	// Name uploads after the source URL so a retried upload replaces the same asset
+	sum := sha1.Sum([]byte(sourceURL))
+	fileName := "import_" + hex.EncodeToString(sum[:])[:20]
+
+	hostedURL, publicID, err := i.mediaStore.UploadFromReader(ctx, bytes.NewReader(data), fileName)
+	if err != nil {
+		return "", err
+	}
+
+	metadata, err := json.Marshal(map[string]string{"source_url": sourceURL})
+	if err != nil {
+		return "", err
+	}
+
+	media := &models.MediaFile{
+		UserID:             ownerID,
+		OriginalName:       path.Base(req.URL.Path),
+		FileName:           fileName,
+		FilePath:           hostedURL,
+		CloudinaryPublicID: publicID,
+		MimeType:           mimeType,
+		FileSize:           int64(len(data)),
+		Metadata:           metadata,
+	}
+	if err := i.mediaRepo.Create(media); err != nil {
+		return "", err
+	}
+
+	run.report.Media.Created++
+	return hostedURL, nil
+}
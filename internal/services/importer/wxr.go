@@ -0,0 +1,155 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kyomel/blog-management/internal/models"
+)
+
+const wxrDateLayout = "2006-01-02 15:04:05"
+
+// Element names are matched by local name only, because the wp: namespace
+// URI changes with every WXR version
+type wxrDocument struct {
+	Channel struct {
+		Authors []struct {
+			Login       string `xml:"author_login"`
+			Email       string `xml:"author_email"`
+			DisplayName string `xml:"author_display_name"`
+		} `xml:"author"`
+		Categories []struct {
+			Nicename    string `xml:"category_nicename"`
+			Name        string `xml:"cat_name"`
+			Description string `xml:"category_description"`
+		} `xml:"category"`
+		Tags []struct {
+			Slug        string `xml:"tag_slug"`
+			Name        string `xml:"tag_name"`
+			Description string `xml:"tag_description"`
+		} `xml:"tag"`
+		Items []wxrItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type wxrItem struct {
+	Title   string `xml:"title"`
+	Creator string `xml:"creator"`
+	// content:encoded and excerpt:encoded share a local name
+	Encoded []struct {
+		XMLName xml.Name
+		Value   string `xml:",chardata"`
+	} `xml:"encoded"`
+	PostID        string `xml:"post_id"`
+	PostDateGMT   string `xml:"post_date_gmt"`
+	PostName      string `xml:"post_name"`
+	Status        string `xml:"status"`
+	PostType      string `xml:"post_type"`
+	AttachmentURL string `xml:"attachment_url"`
+	Terms         []struct {
+		Domain   string `xml:"domain,attr"`
+		Nicename string `xml:"nicename,attr"`
+	} `xml:"category"`
+	PostMeta []struct {
+		Key   string `xml:"meta_key"`
+		Value string `xml:"meta_value"`
+	} `xml:"postmeta"`
+}
+
+// ParseWXR reads a WordPress eXtended RSS export
+func ParseWXR(r io.Reader) (*Document, error) {
+	var wxr wxrDocument
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	if err := decoder.Decode(&wxr); err != nil {
+		return nil, fmt.Errorf("failed to parse WXR: %w", err)
+	}
+
+	doc := &Document{Source: FormatWordPress}
+	channel := wxr.Channel
+
+	for _, a := range channel.Authors {
+		doc.Authors = append(doc.Authors, Author{
+			Key:      a.Login,
+			Email:    a.Email,
+			Username: a.Login,
+			Fullname: a.DisplayName,
+		})
+	}
+
+	for _, c := range channel.Categories {
+		doc.Categories = append(doc.Categories, Term{Name: c.Name, Slug: c.Nicename, Description: c.Description})
+	}
+
+	for _, t := range channel.Tags {
+		doc.Tags = append(doc.Tags, Term{Name: t.Name, Slug: t.Slug, Description: t.Description})
+	}
+
+	// Featured images point at attachment items by post ID
+	attachments := map[string]string{}
+	for _, item := range channel.Items {
+		if item.PostType == "attachment" && item.AttachmentURL != "" {
+			attachments[item.PostID] = item.AttachmentURL
+		}
+	}
+
+	for _, item := range channel.Items {
+		if item.PostType == "attachment" {
+			continue
+		}
+
+		post := Post{
+			SourceID:  item.PostID,
+			Title:     item.Title,
+			Slug:      item.PostName,
+			AuthorKey: item.Creator,
+		}
+
+		for _, enc := range item.Encoded {
+			if strings.Contains(enc.XMLName.Space, "excerpt") {
+				post.Excerpt = enc.Value
+			} else {
+				post.Content = enc.Value
+			}
+		}
+
+		for _, term := range item.Terms {
+			switch term.Domain {
+			case "category":
+				post.CategorySlugs = append(post.CategorySlugs, term.Nicename)
+			case "post_tag":
+				post.TagSlugs = append(post.TagSlugs, term.Nicename)
+			}
+		}
+
+		for _, meta := range item.PostMeta {
+			if meta.Key == "_thumbnail_id" {
+				post.FeaturedImageURL = attachments[meta.Value]
+			}
+		}
+
+		if published, err := time.Parse(wxrDateLayout, item.PostDateGMT); err == nil && published.Year() > 1 {
+			post.PublishedAt = &published
+		}
+
+		switch item.Status {
+		case "publish":
+			post.Status = models.StatusPublished
+		case "draft", "pending", "private", "future":
+			post.Status = models.StatusDraft
+		default:
+			post.SkipReason = fmt.Sprintf("unsupported status %q", item.Status)
+		}
+
+		if item.PostType != "post" {
+			post.SkipReason = fmt.Sprintf("unsupported post type %q", item.PostType)
+		}
+
+		doc.Posts = append(doc.Posts, post)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/services/mailer"
+)
+
+var (
+	ErrTooManyAttempts = errors.New("too many failed login attempts")
+	ErrAccountLocked   = errors.New("account is temporarily locked")
+)
+
+// RetryError wraps ErrTooManyAttempts or ErrAccountLocked with the time the
+// client has to wait
+type RetryError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v; retry after %s", e.Err, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// LoginThrottleConfig controls the delays imposed after failed logins. Both
+// the account and the client IP get FreeAttempts (IPFreeAttempts) failures
+// before each further attempt has to wait BaseDelay, doubling up to MaxDelay.
+type LoginThrottleConfig struct {
+	FreeAttempts   int
+	IPFreeAttempts int
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	// LockoutThreshold consecutive failures lock the account for LockoutDuration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+}
+
+// backoff returns how long to wait after the given number of failures
+func (c LoginThrottleConfig) backoff(failures, free int) time.Duration {
+	if failures <= free {
+		return 0
+	}
+	delay := c.BaseDelay
+	for i := free + 1; i < failures && delay < c.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, c.MaxDelay)
+}
+
+type ipFailures struct {
+	count int
+	last  time.Time
+}
+
+// ipThrottle tracks failed logins per client IP in memory. An IP is forgotten
+// once it has been quiet for MaxDelay.
+type ipThrottle struct {
+	config LoginThrottleConfig
+
+	mu        sync.Mutex
+	failures  map[string]*ipFailures
+	lastSweep time.Time
+}
+
+func newIPThrottle(config LoginThrottleConfig) *ipThrottle {
+	return &ipThrottle{
+		config:    config,
+		failures:  make(map[string]*ipFailures),
+		lastSweep: time.Now(),
+	}
+}
+
+// retryAfter returns how long ip has to wait before its next attempt
+func (t *ipThrottle) retryAfter(ip string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	f, ok := t.failures[ip]
+	if !ok {
+		return 0
+	}
+	wait := f.last.Add(t.config.backoff(f.count, t.config.IPFreeAttempts)).Sub(now)
+	return max(wait, 0)
+}
+
+func (t *ipThrottle) fail(ip string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.lastSweep) > t.config.MaxDelay {
+		for key, f := range t.failures {
+			if now.Sub(f.last) > t.config.MaxDelay {
+				delete(t.failures, key)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	f, ok := t.failures[ip]
+	if !ok || now.Sub(f.last) > t.config.MaxDelay {
+		f = &ipFailures{}
+		t.failures[ip] = f
+	}
+	f.count++
+	f.last = now
+}
+
+type unknownEmailFailures struct {
+	count       int
+	last        time.Time
+	lockedUntil time.Time
+}
+
+// unknownEmailThrottle mirrors the per-account backoff and lockout for
+// emails that have no account, so that a 429 or 423 does not reveal which
+// emails are registered. It is kept in memory and an email is forgotten once
+// it has been quiet for the longer of MaxDelay and LockoutDuration.
+type unknownEmailThrottle struct {
+	config LoginThrottleConfig
+
+	mu        sync.Mutex
+	failures  map[string]*unknownEmailFailures
+	lastSweep time.Time
+}
+
+func newUnknownEmailThrottle(config LoginThrottleConfig) *unknownEmailThrottle {
+	return &unknownEmailThrottle{
+		config:    config,
+		failures:  make(map[string]*unknownEmailFailures),
+		lastSweep: time.Now(),
+	}
+}
+
+func (t *unknownEmailThrottle) ttl() time.Duration {
+	return max(t.config.MaxDelay, t.config.LockoutDuration)
+}
+
+// check returns the error checkAccountThrottle would give an account with
+// the same failures
+func (t *unknownEmailThrottle) check(email string, now time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	f, ok := t.failures[strings.ToLower(email)]
+	if !ok {
+		return nil
+	}
+	if f.lockedUntil.After(now) {
+		return &RetryError{Err: ErrAccountLocked, RetryAfter: f.lockedUntil.Sub(now)}
+	}
+	delay := t.config.backoff(f.count, t.config.FreeAttempts)
+	if wait := f.last.Add(delay).Sub(now); wait > 0 {
+		return &RetryError{Err: ErrTooManyAttempts, RetryAfter: wait}
+	}
+	return nil
+}
+
+// fail counts a failure the way RecordLoginFailure does, restarting the count
+// when the email gets locked
+func (t *unknownEmailThrottle) fail(email string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.lastSweep) > t.ttl() {
+		for key, f := range t.failures {
+			if now.Sub(f.last) > t.ttl() {
+				delete(t.failures, key)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	key := strings.ToLower(email)
+	f, ok := t.failures[key]
+	if !ok || now.Sub(f.last) > t.ttl() {
+		f = &unknownEmailFailures{}
+		t.failures[key] = f
+	}
+	f.count++
+	if f.count >= t.config.LockoutThreshold {
+		f.count = 0
+		f.lockedUntil = now.Add(t.config.LockoutDuration)
+	}
+	f.last = now
+}
+
+// checkAccountThrottle returns a RetryError while user is locked or has to
+// wait after a failed attempt
+func (s *authService) checkAccountThrottle(ctx context.Context, user *models.User, client models.ClientInfo, now time.Time) error {
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		s.auditLogin(ctx, &user.ID, models.ActionLoginFailed, client, user.Email, "account_locked")
+		return &RetryError{Err: ErrAccountLocked, RetryAfter: user.LockedUntil.Sub(now)}
+	}
+	if user.LastFailedLoginAt != nil {
+		delay := s.loginThrottle.backoff(user.FailedLoginAttempts, s.loginThrottle.FreeAttempts)
+		if wait := user.LastFailedLoginAt.Add(delay).Sub(now); wait > 0 {
+			s.auditLogin(ctx, &user.ID, models.ActionLoginFailed, client, user.Email, "account_throttled")
+			return &RetryError{Err: ErrTooManyAttempts, RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+// clearLoginFailures resets the failure count after a complete login
+func (s *authService) clearLoginFailures(ctx context.Context, user *models.User) error {
+	if user.FailedLoginAttempts == 0 && user.LockedUntil == nil {
+		return nil
+	}
+	return s.userRepo.ClearLoginFailures(ctx, user.ID)
+}
+
+// loginFailed records a wrong password or second factor for user, reason
+// naming which, and emails them if this locked the account
+func (s *authService) loginFailed(ctx context.Context, user *models.User, client models.ClientInfo, now time.Time, reason string) error {
+	s.ipThrottle.fail(client.IPAddress, now)
+
+	lockUntil := now.Add(s.loginThrottle.LockoutDuration)
+	locked, err := s.userRepo.RecordLoginFailure(ctx, user.ID, s.loginThrottle.LockoutThreshold, lockUntil)
+	if err != nil {
+		return err
+	}
+
+	if !locked {
+		s.auditLogin(ctx, &user.ID, models.ActionLoginFailed, client, user.Email, reason)
+		return ErrInvalidCredentials
+	}
+
+	s.auditLogin(ctx, &user.ID, models.ActionLoginFailed, client, user.Email, reason+"_locked")
+	s.sendInBackground(mailer.Message{
+		To:      user.Email,
+		Subject: "Your account has been locked",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nAfter %d failed login attempts your account has been locked until %s. "+
+				"The last attempt came from %s.\n\n"+
+				"If this was not you, someone may be guessing your password. "+
+				"Consider resetting it once the lock expires.\n",
+			user.Username, s.loginThrottle.LockoutThreshold, lockUntil.UTC().Format(time.RFC1123), client.IPAddress),
+	}, "account locked", user.ID)
+	return ErrInvalidCredentials
+}
+
+// UnlockAccount lifts a lockout and resets the user's failure count
+func (s *authService) UnlockAccount(ctx context.Context, userID, adminID uuid.UUID, client models.ClientInfo) error {
+	err := s.userRepo.ClearLoginFailures(ctx, userID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	s.audit(ctx, &adminID, models.ActionUnlock, client, map[string]interface{}{"user_id": userID})
+	return nil
+}
+
+// auditLogin records a login attempt; reason, if any, qualifies the outcome
+func (s *authService) auditLogin(ctx context.Context, userID *uuid.UUID, action models.AuditAction, client models.ClientInfo, email, reason string) {
+	values := map[string]interface{}{"email": email}
+	if reason != "" {
+		values["reason"] = reason
+	}
+	s.audit(ctx, userID, action, client, values)
+}
+
+// audit writes an entry about the users table. Failures are logged rather
+// than failing the request.
+func (s *authService) audit(ctx context.Context, userID *uuid.UUID, action models.AuditAction, client models.ClientInfo, values interface{}) {
+	newValues, err := json.Marshal(values)
+	if err != nil {
+		log.Printf("Failed to encode audit log values: %v", err)
+		return
+	}
+
+	err = s.auditRepo.Create(ctx, &models.AuditLog{
+		UserID:    userID,
+		TableName: "users",
+		Action:    action,
+		NewValues: newValues,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	})
+	if err != nil {
+		log.Printf("Failed to write audit log: %v", err)
+	}
+}
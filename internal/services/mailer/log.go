@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogMailer is meant for development. It writes each message to a .eml file
+// in dir, or to the log when dir is empty.
+type LogMailer struct {
+	dir  string
+	from string
+}
+
+func NewLogMailer(dir, from string) *LogMailer {
+	return &LogMailer{dir: dir, from: from}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if !validHeader(msg.To) || !validHeader(msg.Subject) {
+		return errors.New("invalid email header")
+	}
+	data := format(m.from, msg)
+
+	if m.dir == "" {
+		log.Printf("Email to %s:\n%s", msg.To, data)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	recipient := strings.NewReplacer("/", "_", "\\", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000"), recipient)
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o644)
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/utils"
+)
+
+var (
+	ErrPersonalTokenNotFound = errors.New("personal access token not found")
+	ErrInvalidTokenScope     = errors.New("token scopes must be one or more known scopes")
+	ErrInvalidTokenExpiry    = errors.New("token expiry must be in the future")
+	ErrTooManyPersonalTokens = errors.New("too many personal access tokens")
+)
+
+const (
+	// maxPersonalTokens is how many tokens a user may hold at once
+	maxPersonalTokens = 50
+	// personalTokenDisplayChars is how much of a token is kept to identify it
+	personalTokenDisplayChars = len(utils.PersonalTokenPrefix) + 8
+	// personalTokenTouchInterval limits how often last_used_at is written
+	personalTokenTouchInterval = time.Minute
+)
+
+type PersonalTokenService interface {
+	Create(ctx context.Context, userID uuid.UUID, req models.CreatePersonalTokenRequest) (*models.CreatePersonalTokenResponse, error)
+	List(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type personalTokenService struct {
+	repo *repositories.PersonalTokenRepository
+}
+
+func NewPersonalTokenService(repo *repositories.PersonalTokenRepository) PersonalTokenService {
+	return &personalTokenService{
+		repo: repo,
+	}
+}
+
+// Create issues a token. The plaintext is returned here and never again; only
+// its hash is stored.
+func (s *personalTokenService) Create(ctx context.Context, userID uuid.UUID, req models.CreatePersonalTokenRequest) (*models.CreatePersonalTokenResponse, error) {
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidTokenExpiry
+	}
+
+	count, err := s.repo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxPersonalTokens {
+		return nil, ErrTooManyPersonalTokens
+	}
+
+	token, err := utils.GeneratePersonalToken()
+	if err != nil {
+		return nil, err
+	}
+
+	pat := models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    token[:personalTokenDisplayChars],
+		TokenHash: utils.HashToken(token),
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, &pat); err != nil {
+		return nil, err
+	}
+
+	return &models.CreatePersonalTokenResponse{
+		PersonalAccessToken: pat,
+		Token:               token,
+	}, nil
+}
+
+func (s *personalTokenService) List(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *personalTokenService) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	err := s.repo.Delete(ctx, userID, id)
+	if errors.Is(err, repositories.ErrPersonalTokenNotFound) {
+		return ErrPersonalTokenNotFound
+	}
+	return err
+}
+
+// normalizeScopes rejects unknown scopes and drops duplicates
+func normalizeScopes(requested []string) (models.TokenScopes, error) {
+	if len(requested) == 0 {
+		return nil, ErrInvalidTokenScope
+	}
+
+	scopes := models.TokenScopes{}
+	for _, scope := range requested {
+		if !slices.Contains(models.TokenScopeNames, scope) {
+			return nil, ErrInvalidTokenScope
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// validatePersonalToken authenticates a personal access token. Unlike
+// sessions these survive password changes; they stop working when they
+// expire, are revoked or the user is deactivated.
+func (s *authService) validatePersonalToken(ctx context.Context, token string) (*utils.JWTClaims, error) {
+	pat, err := s.personalTokenRepo.FindLiveByHash(ctx, utils.HashToken(token))
+	if errors.Is(err, repositories.ErrPersonalTokenNotFound) {
+		return nil, utils.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, pat.UserID)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		return nil, utils.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, utils.ErrInvalidToken
+	}
+	if s.blockedUnverified(user) {
+		return nil, ErrEmailNotVerified
+	}
+
+	if err := s.personalTokenRepo.Touch(ctx, pat.ID, personalTokenTouchInterval); err != nil {
+		log.Printf("Failed to record use of personal access token %s: %v", pat.ID, err)
+	}
+
+	return &utils.JWTClaims{
+		UserID:                 user.ID,
+		Username:               user.Username,
+		Email:                  user.Email,
+		Role:                   string(user.Role),
+		TokenVersion:           user.TokenVersion,
+		EmailVerified:          user.EmailVerifiedAt != nil,
+		TwoFactorSetupRequired: s.twoFactorSetupRequired(user),
+		Scopes:                 append([]string{}, pat.Scopes...),
+	}, nil
+}
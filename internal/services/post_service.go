@@ -11,45 +11,76 @@ import (
 	"github.com/google/uuid"
 	"github.com/kyomel/blog-management/internal/models"
 	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/utils"
 )
 
 var (
-	ErrPostNotFound     = errors.New("post not found")
-	ErrPostSlugConflict = errors.New("post slug already exists")
+	ErrPostNotFound         = errors.New("post not found")
+	ErrPostSlugConflict     = errors.New("post slug already exists")
+	ErrInvalidContentFormat = utils.ErrInvalidContentFormat
+	ErrInvalidPostSort      = errors.New("invalid post sort field")
+	ErrInvalidBulkAction    = errors.New("invalid bulk action")
+	ErrBulkTargetRequired   = errors.New("ids or filter is required")
+	ErrBulkInvalidStatus    = errors.New("filter status must be one of draft, published, archived")
+	ErrBulkTooManyPosts     = errors.New("too many posts for a single bulk operation")
+	ErrBulkCategoryRequired = errors.New("category_id is required for set_category")
+	ErrBulkTagsRequired     = errors.New("tag_ids is required for tag actions")
+	ErrBulkCategoryNotFound = errors.New("category not found")
+	ErrBulkTagNotFound      = errors.New("tag not found")
 )
 
+// maxBulkPosts caps how many posts a single bulk operation may touch
+const maxBulkPosts = 1000
+
 // PostService defines the interface for post-related operations
 type PostService interface {
 	Create(ctx context.Context, req *models.CreatePostRequest) (*models.PostResponse, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.PostResponse, error)
 	GetBySlug(ctx context.Context, slug string) (*models.PostResponse, error)
 	GetAll(ctx context.Context, filter *models.PostFilter, page, pageSize int) (*models.PaginatedPostResponse, error)
+	GetPage(ctx context.Context, filter *models.PostFilter, cursor string, pageSize int, withTotal bool) (*models.CursorPostResponse, error)
 	Update(ctx context.Context, id uuid.UUID, req *models.UpdatePostRequest) (*models.PostResponse, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	Publish(ctx context.Context, id uuid.UUID) (*models.PostResponse, error)
-	IncrementViewCount(ctx context.Context, id uuid.UUID) error
+	Bulk(ctx context.Context, req *models.BulkPostRequest) (*models.BulkPostResponse, error)
 }
 
 type postService struct {
-	repo *repositories.PostRepository
+	repo            *repositories.PostRepository
+	slugHistoryRepo *repositories.SlugHistoryRepository
 }
 
 // NewPostService creates a new instance of PostService
-func NewPostService(repo *repositories.PostRepository) PostService {
+func NewPostService(repo *repositories.PostRepository, slugHistoryRepo *repositories.SlugHistoryRepository) PostService {
 	return &postService{
-		repo: repo,
+		repo:            repo,
+		slugHistoryRepo: slugHistoryRepo,
 	}
 }
 
 // Create creates a new post
 func (s *postService) Create(ctx context.Context, req *models.CreatePostRequest) (*models.PostResponse, error) {
-	// Check if slug already exists
-	existingPost, err := s.repo.GetBySlug(req.Slug)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return nil, err
+	if req.Slug == "" {
+		// Generate a slug from the title when none is given
+		slug, err := generateSlug(req.Title, "post", s.slugTaken)
+		if err != nil {
+			return nil, err
+		}
+		req.Slug = slug
+	} else {
+		// Check if slug already exists
+		taken, err := s.slugTaken(req.Slug)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrPostSlugConflict
+		}
 	}
-	if existingPost != nil {
-		return nil, ErrPostSlugConflict
+
+	format := req.ContentFormat
+	if format == "" {
+		format = models.FormatHTML
 	}
 
 	// Create new post
@@ -60,20 +91,29 @@ func (s *postService) Create(ctx context.Context, req *models.CreatePostRequest)
 		Title:            req.Title,
 		Slug:             req.Slug,
 		Content:          req.Content,
+		ContentFormat:    format,
 		Excerpt:          req.Excerpt,
 		FeaturedImageURL: req.FeaturedImageURL,
 		Status:           req.Status,
 		IsFeatured:       req.IsFeatured,
 		Metadata:         req.Metadata,
 		// Use pointers for time fields
-		CreatedAt:        func() *time.Time { now := time.Now(); return &now }(),
-		UpdatedAt:        func() *time.Time { now := time.Now(); return &now }(),
+		CreatedAt: func() *time.Time { now := time.Now(); return &now }(),
+		UpdatedAt: func() *time.Time { now := time.Now(); return &now }(),
+	}
+
+	// Render content and derive reading stats
+	if err := utils.ApplyPostContent(post); err != nil {
+		return nil, err
 	}
 
 	// Set PublishedAt if status is published
 	if req.Status == models.StatusPublished {
 		now := time.Now()
 		post.PublishedAt = &now
+		if req.PublishedAt != nil {
+			post.PublishedAt = req.PublishedAt
+		}
 	}
 
 	// Create post and associate tags
@@ -92,18 +132,16 @@ func (s *postService) Create(ctx context.Context, req *models.CreatePostRequest)
 
 // GetByID retrieves a post by its ID
 func (s *postService) GetByID(ctx context.Context, id uuid.UUID) (*models.PostResponse, error) {
-	post, err := s.repo.GetByID(id)
+	post, err := s.findPost(id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrPostNotFound
-		}
 		return nil, err
 	}
 
 	return s.mapPostToResponse(post), nil
 }
 
-// GetBySlug retrieves a post by its slug
+// GetBySlug retrieves a post by its slug. If the slug was used by a post before
+// it was renamed, that post is returned and its Slug differs from the one requested.
 func (s *postService) GetBySlug(ctx context.Context, slug string) (*models.PostResponse, error) {
 	post, err := s.repo.GetBySlug(slug)
 	if err != nil {
@@ -113,6 +151,24 @@ func (s *postService) GetBySlug(ctx context.Context, slug string) (*models.PostR
 		return nil, err
 	}
 
+	if post == nil {
+		postID, err := s.slugHistoryRepo.FindEntityID(models.SlugEntityPost, slug)
+		if err != nil {
+			return nil, err
+		}
+		if postID == nil {
+			return nil, ErrPostNotFound
+		}
+
+		post, err = s.repo.GetByID(*postID)
+		if err != nil {
+			return nil, err
+		}
+		if post == nil {
+			return nil, ErrPostNotFound
+		}
+	}
+
 	return s.mapPostToResponse(post), nil
 }
 
@@ -133,6 +189,9 @@ func (s *postService) GetAll(ctx context.Context, filter *models.PostFilter, pag
 	if filter == nil {
 		filter = &models.PostFilter{}
 	}
+	if filter.SortBy != "" && !repositories.IsValidPostSort(filter.SortBy) {
+		return nil, ErrInvalidPostSort
+	}
 	filter.Limit = pageSize
 	filter.Offset = offset
 
@@ -160,14 +219,66 @@ func (s *postService) GetAll(ctx context.Context, filter *models.PostFilter, pag
 	}, nil
 }
 
+// GetPage retrieves posts with keyset pagination. The cursor is the
+// next_cursor of the previous page, or empty for the first page. Counting all
+// matching posts is comparatively expensive and only done when withTotal is set.
+func (s *postService) GetPage(ctx context.Context, filter *models.PostFilter, cursor string, pageSize int, withTotal bool) (*models.CursorPostResponse, error) {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if filter == nil {
+		filter = &models.PostFilter{}
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = "created_at"
+		filter.SortDesc = true
+	}
+	if !repositories.IsValidPostSort(filter.SortBy) {
+		return nil, ErrInvalidPostSort
+	}
+
+	after, err := decodeCursor(cursor, filter.SortBy, filter.SortDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra post to learn whether another page follows
+	posts, err := s.repo.GetPage(filter, after, pageSize+1)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return nil, ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CursorPostResponse{
+		Posts:    make([]*models.PostResponse, 0, len(posts)),
+		PageSize: pageSize,
+	}
+	if len(posts) > pageSize {
+		posts = posts[:pageSize]
+		result.NextCursor = utils.EncodeCursor(repositories.PostCursor(filter, posts[len(posts)-1]))
+	}
+	for _, post := range posts {
+		result.Posts = append(result.Posts, s.mapPostToResponse(post))
+	}
+
+	if withTotal {
+		total, err := s.repo.Count(filter)
+		if err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+
+	return result, nil
+}
+
 // Update updates an existing post
 func (s *postService) Update(ctx context.Context, id uuid.UUID, req *models.UpdatePostRequest) (*models.PostResponse, error) {
 	// Get existing post
-	post, err := s.repo.GetByID(id)
+	post, err := s.findPost(id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrPostNotFound
-		}
 		return nil, err
 	}
 
@@ -195,6 +306,14 @@ func (s *postService) Update(ctx context.Context, id uuid.UUID, req *models.Upda
 	if req.Content != "" {
 		post.Content = req.Content
 	}
+	if req.ContentFormat != "" {
+		post.ContentFormat = req.ContentFormat
+	}
+	if req.Content != "" || req.ContentFormat != "" {
+		if err := utils.ApplyPostContent(post); err != nil {
+			return nil, err
+		}
+	}
 	if req.Excerpt != "" {
 		post.Excerpt = req.Excerpt
 	}
@@ -209,6 +328,9 @@ func (s *postService) Update(ctx context.Context, id uuid.UUID, req *models.Upda
 			post.PublishedAt = &now
 		}
 	}
+	if req.PublishedAt != nil {
+		post.PublishedAt = req.PublishedAt
+	}
 	if req.IsFeatured != nil {
 		post.IsFeatured = *req.IsFeatured
 	}
@@ -233,14 +355,20 @@ func (s *postService) Update(ctx context.Context, id uuid.UUID, req *models.Upda
 	return s.mapPostToResponse(updatedPost), nil
 }
 
+// findPost loads a post, failing with ErrPostNotFound if there is none
+func (s *postService) findPost(id uuid.UUID) (*models.Post, error) {
+	post, err := s.repo.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && post == nil) {
+		return nil, ErrPostNotFound
+	}
+	return post, err
+}
+
 // Delete soft-deletes a post
 func (s *postService) Delete(ctx context.Context, id uuid.UUID) error {
 	// Check if post exists
-	post, err := s.repo.GetByID(id)
+	post, err := s.findPost(id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrPostNotFound
-		}
 		return err
 	}
 
@@ -251,11 +379,8 @@ func (s *postService) Delete(ctx context.Context, id uuid.UUID) error {
 // Publish changes a post's status to published and sets the published_at timestamp
 func (s *postService) Publish(ctx context.Context, id uuid.UUID) (*models.PostResponse, error) {
 	// Get existing post
-	post, err := s.repo.GetByID(id)
+	post, err := s.findPost(id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrPostNotFound
-		}
 		return nil, err
 	}
 
@@ -279,9 +404,91 @@ func (s *postService) Publish(ctx context.Context, id uuid.UUID) (*models.PostRe
 	return s.mapPostToResponse(updatedPost), nil
 }
 
-// IncrementViewCount increments the view count of a post
-func (s *postService) IncrementViewCount(ctx context.Context, id uuid.UUID) error {
-	return s.repo.IncrementViewCount(id)
+// Bulk applies one action to the posts listed in req.IDs, or to every post
+// matching req.Filter when no IDs are given
+func (s *postService) Bulk(ctx context.Context, req *models.BulkPostRequest) (*models.BulkPostResponse, error) {
+	switch req.Action {
+	case models.BulkActionPublish, models.BulkActionArchive, models.BulkActionDelete,
+		models.BulkActionRestore, models.BulkActionFeature, models.BulkActionUnfeature:
+	case models.BulkActionSetCategory:
+		if req.CategoryID == nil || *req.CategoryID == uuid.Nil {
+			return nil, ErrBulkCategoryRequired
+		}
+	case models.BulkActionAddTags, models.BulkActionRemoveTags:
+		if len(req.TagIDs) == 0 {
+			return nil, ErrBulkTagsRequired
+		}
+	default:
+		return nil, ErrInvalidBulkAction
+	}
+
+	ids := req.IDs
+	if len(ids) == 0 {
+		if req.Filter == nil {
+			return nil, ErrBulkTargetRequired
+		}
+		switch req.Filter.Status {
+		case "", models.StatusDraft, models.StatusPublished, models.StatusArchived:
+		default:
+			return nil, ErrBulkInvalidStatus
+		}
+
+		filter := &models.PostFilter{
+			Status:     req.Filter.Status,
+			CategoryID: req.Filter.CategoryID,
+			AuthorID:   req.Filter.AuthorID,
+			IsFeatured: req.Filter.IsFeatured,
+			Search:     req.Filter.Search,
+		}
+
+		// Restoring selects from the trash, every other action from live posts
+		var err error
+		ids, err = s.repo.FindIDs(filter, req.Action == models.BulkActionRestore, maxBulkPosts+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ids) > maxBulkPosts {
+		return nil, ErrBulkTooManyPosts
+	}
+
+	results, err := s.repo.BulkApply(ids, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrBulkCategoryNotFound):
+			return nil, ErrBulkCategoryNotFound
+		case errors.Is(err, repositories.ErrBulkTagNotFound):
+			return nil, ErrBulkTagNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	response := &models.BulkPostResponse{
+		Action:  req.Action,
+		DryRun:  req.DryRun,
+		Matched: len(ids),
+		Results: results,
+	}
+	for _, result := range results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+
+	return response, nil
+}
+
+// slugTaken reports whether a live post already uses the slug
+func (s *postService) slugTaken(slug string) (bool, error) {
+	post, err := s.repo.GetBySlug(slug)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+	return post != nil, nil
 }
 
 // mapPostToResponse maps a Post model to a PostResponse
@@ -299,6 +506,17 @@ func (s *postService) mapPostToResponse(post *models.Post) *models.PostResponse
 		}
 	}
 
+	var outline []models.PostHeading
+	if len(post.Outline) > 0 {
+		_ = json.Unmarshal(post.Outline, &outline)
+	}
+
+	// Posts saved before content rendering existed have no cached HTML
+	contentHTML := post.ContentHTML
+	if contentHTML == "" && post.Content != "" {
+		contentHTML, _ = utils.RenderContent(post.ContentFormat, post.Content)
+	}
+
 	return &models.PostResponse{
 		ID:               post.ID,
 		AuthorID:         post.AuthorID,
@@ -306,6 +524,11 @@ func (s *postService) mapPostToResponse(post *models.Post) *models.PostResponse
 		Title:            post.Title,
 		Slug:             post.Slug,
 		Content:          post.Content,
+		ContentFormat:    post.ContentFormat,
+		ContentHTML:      contentHTML,
+		WordCount:        post.WordCount,
+		ReadingTime:      post.ReadingTime,
+		Outline:          outline,
 		Excerpt:          post.Excerpt,
 		FeaturedImageURL: post.FeaturedImageURL,
 		Status:           post.Status,
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/kyomel/blog-management/internal/utils"
+)
+
+// maxSlugAttempts bounds the number of numeric suffixes tried before giving up
+const maxSlugAttempts = 100
+
+// generateSlug derives a slug from text, appending -2, -3, ... until taken reports
+// it free. fallback is used when text has no characters usable in a slug.
+func generateSlug(text, fallback string, taken func(slug string) (bool, error)) (string, error) {
+	base := utils.Slugify(text)
+	if base == "" {
+		base = fallback
+	}
+
+	slug := base
+	for i := 2; i <= maxSlugAttempts; i++ {
+		exists, err := taken(slug)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	return "", fmt.Errorf("could not generate a unique slug for %q", text)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/kyomel/blog-management/internal/models"
 	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/utils"
 )
 
 var (
@@ -21,20 +22,24 @@ type TagService interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.TagResponse, error)
 	GetBySlug(ctx context.Context, slug string) (*models.TagResponse, error)
 	GetAll(ctx context.Context, page, pageSize int) (*models.PaginatedTagResponse, error)
+	GetPage(ctx context.Context, cursor string, pageSize int, withTotal bool) (*models.CursorTagResponse, error)
 	Update(ctx context.Context, id uuid.UUID, req *models.UpdateTagRequest) (*models.TagResponse, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetTagsByPostID(ctx context.Context, postID uuid.UUID) ([]*models.TagResponse, error)
 	AddTagsToPost(ctx context.Context, postID uuid.UUID, tagIDs []uuid.UUID) error
 	GetPostsByTagID(ctx context.Context, tagID uuid.UUID, page, pageSize int) (*models.PaginatedPostResponse, error)
+	GetPostsPageByTagID(ctx context.Context, tagID uuid.UUID, cursor string, pageSize int, withTotal bool) (*models.CursorPostResponse, error)
 }
 
 type tagService struct {
-	repo *repositories.TagRepository
+	repo            *repositories.TagRepository
+	slugHistoryRepo *repositories.SlugHistoryRepository
 }
 
-func NewTagService(repo *repositories.TagRepository) TagService {
+func NewTagService(repo *repositories.TagRepository, slugHistoryRepo *repositories.SlugHistoryRepository) TagService {
 	return &tagService{
-		repo: repo,
+		repo:            repo,
+		slugHistoryRepo: slugHistoryRepo,
 	}
 }
 
@@ -47,12 +52,20 @@ func (s *tagService) Create(ctx context.Context, req *models.CreateTagRequest) (
 		return nil, ErrTagNameConflict
 	}
 
-	other, err = s.repo.GetBySlug(req.Slug)
-	if err != nil {
-		return nil, err
-	}
-	if other != nil {
-		return nil, ErrTagSlugConflict
+	if req.Slug == "" {
+		slug, err := generateSlug(req.Name, "tag", s.slugTaken)
+		if err != nil {
+			return nil, err
+		}
+		req.Slug = slug
+	} else {
+		other, err = s.repo.GetBySlug(req.Slug)
+		if err != nil {
+			return nil, err
+		}
+		if other != nil {
+			return nil, ErrTagSlugConflict
+		}
 	}
 
 	tag := &models.Tag{
@@ -79,13 +92,29 @@ func (s *tagService) GetByID(ctx context.Context, id uuid.UUID) (*models.TagResp
 	return tag.ToResponse(), nil
 }
 
+// GetBySlug also resolves slugs the tag used before it was renamed, in which
+// case the returned Slug differs from the one requested
 func (s *tagService) GetBySlug(ctx context.Context, slug string) (*models.TagResponse, error) {
 	tag, err := s.repo.GetBySlug(slug)
 	if err != nil {
 		return nil, err
 	}
 	if tag == nil {
-		return nil, ErrTagNotFound
+		id, err := s.slugHistoryRepo.FindEntityID(models.SlugEntityTag, slug)
+		if err != nil {
+			return nil, err
+		}
+		if id == nil {
+			return nil, ErrTagNotFound
+		}
+
+		tag, err = s.repo.GetByID(*id)
+		if err != nil {
+			return nil, err
+		}
+		if tag == nil {
+			return nil, ErrTagNotFound
+		}
 	}
 	return tag.ToResponse(), nil
 }
@@ -123,6 +152,48 @@ func (s *tagService) GetAll(ctx context.Context, page, pageSize int) (*models.Pa
 	}, nil
 }
 
+// GetPage retrieves tags in name order with keyset pagination
+func (s *tagService) GetPage(ctx context.Context, cursor string, pageSize int, withTotal bool) (*models.CursorTagResponse, error) {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	after, err := decodeCursor(cursor, repositories.NameSort, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.GetPage(after, pageSize+1)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return nil, ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CursorTagResponse{
+		Data:     make([]*models.TagResponse, 0, len(tags)),
+		PageSize: pageSize,
+	}
+	if len(tags) > pageSize {
+		tags = tags[:pageSize]
+		result.NextCursor = utils.EncodeCursor(repositories.TagCursor(tags[len(tags)-1]))
+	}
+	for _, t := range tags {
+		result.Data = append(result.Data, t.ToResponse())
+	}
+
+	if withTotal {
+		total, err := s.repo.Count()
+		if err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+
+	return result, nil
+}
+
 func (s *tagService) Update(ctx context.Context, id uuid.UUID, req *models.UpdateTagRequest) (*models.TagResponse, error) {
 	existing, err := s.repo.GetByID(id)
 	if err != nil {
@@ -165,6 +236,15 @@ func (s *tagService) Update(ctx context.Context, id uuid.UUID, req *models.Updat
 	return existing.ToResponse(), nil
 }
 
+// slugTaken reports whether a live tag already uses the slug
+func (s *tagService) slugTaken(slug string) (bool, error) {
+	tag, err := s.repo.GetBySlug(slug)
+	if err != nil {
+		return false, err
+	}
+	return tag != nil, nil
+}
+
 func (s *tagService) Delete(ctx context.Context, id uuid.UUID) error {
 	tag, err := s.repo.GetByID(id)
 	if err != nil {
@@ -234,35 +314,7 @@ func (s *tagService) GetPostsByTagID(ctx context.Context, tagID uuid.UUID, page,
 
 	var responsePosts []*models.PostResponse
 	for _, p := range posts {
-		// Map each post to a response using the same pattern as in post_service.go
-		var metadata interface{}
-		if len(p.Metadata) > 0 {
-			if err := json.Unmarshal(p.Metadata, &metadata); err != nil {
-				// If unmarshal fails, use the raw bytes
-				metadata = p.Metadata
-			}
-		}
-		
-		responsePosts = append(responsePosts, &models.PostResponse{
-			ID:               p.ID,
-			AuthorID:         p.AuthorID,
-			CategoryID:       p.CategoryID,
-			Title:            p.Title,
-			Slug:             p.Slug,
-			Content:          p.Content,
-			Excerpt:          p.Excerpt,
-			FeaturedImageURL: p.FeaturedImageURL,
-			Status:           p.Status,
-			ViewCount:        p.ViewCount,
-			IsFeatured:       p.IsFeatured,
-			PublishedAt:      p.PublishedAt,
-			CreatedAt:        p.CreatedAt,
-			UpdatedAt:        p.UpdatedAt,
-			Metadata:         metadata,
-			Author:           p.Author,
-			Category:         p.Category,
-			Tags:             p.Tags,
-		})
+		responsePosts = append(responsePosts, mapTagPost(p))
 	}
 
 	return &models.PaginatedPostResponse{
@@ -273,3 +325,86 @@ func (s *tagService) GetPostsByTagID(ctx context.Context, tagID uuid.UUID, page,
 		TotalPages: totalPages,
 	}, nil
 }
+
+// GetPostsPageByTagID retrieves a tag's posts, newest first, with keyset pagination
+func (s *tagService) GetPostsPageByTagID(ctx context.Context, tagID uuid.UUID, cursor string, pageSize int, withTotal bool) (*models.CursorPostResponse, error) {
+	tag, err := s.repo.GetByID(tagID)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, ErrTagNotFound
+	}
+
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	after, err := decodeCursor(cursor, repositories.TagPostsSort, true)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.repo.GetPostsPageByTagID(tagID, after, pageSize+1)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return nil, ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CursorPostResponse{
+		Posts:    make([]*models.PostResponse, 0, len(posts)),
+		PageSize: pageSize,
+	}
+	if len(posts) > pageSize {
+		posts = posts[:pageSize]
+		result.NextCursor = utils.EncodeCursor(repositories.TagPostCursor(posts[len(posts)-1]))
+	}
+	for _, p := range posts {
+		result.Posts = append(result.Posts, mapTagPost(p))
+	}
+
+	if withTotal {
+		total, err := s.repo.CountPostsByTagID(tagID)
+		if err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+
+	return result, nil
+}
+
+// mapTagPost maps a post listed under a tag to its response
+func mapTagPost(p *models.Post) *models.PostResponse {
+	// Map each post to a response using the same pattern as in post_service.go
+	var metadata interface{}
+	if len(p.Metadata) > 0 {
+		if err := json.Unmarshal(p.Metadata, &metadata); err != nil {
+			// If unmarshal fails, use the raw bytes
+			metadata = p.Metadata
+		}
+	}
+
+	return &models.PostResponse{
+		ID:               p.ID,
+		AuthorID:         p.AuthorID,
+		CategoryID:       p.CategoryID,
+		Title:            p.Title,
+		Slug:             p.Slug,
+		Content:          p.Content,
+		Excerpt:          p.Excerpt,
+		FeaturedImageURL: p.FeaturedImageURL,
+		Status:           p.Status,
+		ViewCount:        p.ViewCount,
+		IsFeatured:       p.IsFeatured,
+		PublishedAt:      p.PublishedAt,
+		CreatedAt:        p.CreatedAt,
+		UpdatedAt:        p.UpdatedAt,
+		Metadata:         metadata,
+		Author:           p.Author,
+		Category:         p.Category,
+		Tags:             p.Tags,
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+)
+
+var (
+	ErrTrashItemNotFound = errors.New("trash item not found")
+	ErrTrashSlugConflict = errors.New("slug is used by another item")
+	ErrTrashItemInUse    = errors.New("trash item is still referenced")
+	ErrInvalidTrashType  = errors.New("invalid trash type")
+)
+
+type TrashService interface {
+	GetAll(ctx context.Context, entityType models.TrashEntityType, page, pageSize int) (*models.PaginatedTrashResponse, error)
+	Restore(ctx context.Context, entityType models.TrashEntityType, id uuid.UUID) error
+	Purge(ctx context.Context, entityType models.TrashEntityType, id uuid.UUID) error
+	PurgeExpired(ctx context.Context, retention time.Duration) (int, error)
+	RunPurgeJob(ctx context.Context, interval, retention time.Duration)
+}
+
+type trashService struct {
+	repo *repositories.TrashRepository
+}
+
+func NewTrashService(repo *repositories.TrashRepository) TrashService {
+	return &trashService{
+		repo: repo,
+	}
+}
+
+func (s *trashService) GetAll(ctx context.Context, entityType models.TrashEntityType, page, pageSize int) (*models.PaginatedTrashResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	offset := (page - 1) * pageSize
+
+	items, total, err := s.repo.GetAll(entityType, pageSize, offset)
+	if err != nil {
+		return nil, mapTrashError(err)
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	if items == nil {
+		items = []*models.TrashItem{}
+	}
+
+	return &models.PaginatedTrashResponse{
+		Data:       items,
+		Total:      int64(total),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (s *trashService) Restore(ctx context.Context, entityType models.TrashEntityType, id uuid.UUID) error {
+	return mapTrashError(s.repo.Restore(entityType, id))
+}
+
+func (s *trashService) Purge(ctx context.Context, entityType models.TrashEntityType, id uuid.UUID) error {
+	return mapTrashError(s.repo.Purge(entityType, id))
+}
+
+// PurgeExpired permanently deletes everything that has been in the trash longer than retention
+func (s *trashService) PurgeExpired(ctx context.Context, retention time.Duration) (int, error) {
+	return s.repo.PurgeDeletedBefore(time.Now().Add(-retention))
+}
+
+// RunPurgeJob calls PurgeExpired every interval until ctx is cancelled
+func (s *trashService) RunPurgeJob(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeExpired(ctx, retention)
+		if err != nil {
+			log.Printf("Trash purge failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Trash purge removed %d items", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func mapTrashError(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrTrashItemNotFound):
+		return ErrTrashItemNotFound
+	case errors.Is(err, repositories.ErrTrashSlugConflict):
+		return ErrTrashSlugConflict
+	case errors.Is(err, repositories.ErrTrashItemInUse):
+		return ErrTrashItemInUse
+	case errors.Is(err, repositories.ErrInvalidTrashType):
+		return ErrInvalidTrashType
+	default:
+		return err
+	}
+}
@@ -2,20 +2,52 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"log"
 
 	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
 	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/utils"
 )
 
+var (
+	ErrLastAdmin        = errors.New("cannot demote, deactivate or delete the last active admin")
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrEmailConflict    = errors.New("email already exists")
+	ErrUsernameConflict = errors.New("username already exists")
+)
+
+// AvatarStore removes avatar images from the storage backend
+type AvatarStore interface {
+	DeleteAvatar(ctx context.Context, avatarURL string, userID string) error
+}
+
 // UserService handles user-related business logic
 type UserService struct {
-	repo repositories.UserRepository
+	repo              repositories.UserRepository
+	personalTokenRepo *repositories.PersonalTokenRepository
+	auditRepo         *repositories.AuditRepository
+	authService       AuthService
+	avatars           AvatarStore
 }
 
-// NewUserService creates a new instance of UserService
-func NewUserService(repo repositories.UserRepository) *UserService {
+// NewUserService creates a new instance of UserService. authService sends
+// the verification link after an email change.
+func NewUserService(
+	repo repositories.UserRepository,
+	personalTokenRepo *repositories.PersonalTokenRepository,
+	auditRepo *repositories.AuditRepository,
+	authService AuthService,
+	avatars AvatarStore,
+) *UserService {
 	return &UserService{
-		repo: repo,
+		repo:              repo,
+		personalTokenRepo: personalTokenRepo,
+		auditRepo:         auditRepo,
+		authService:       authService,
+		avatars:           avatars,
 	}
 }
 
@@ -30,3 +62,267 @@ func (s *UserService) UpdateAvatarURL(ctx context.Context, userID string, avatar
 	// Call the repository to update the avatar URL
 	return s.repo.UpdateAvatarURL(ctx, id, avatarURL)
 }
+
+// GetProfile returns the user's own profile
+func (s *UserService) GetProfile(ctx context.Context, userID uuid.UUID) (*models.UserResponse, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+	response := userResponse(user)
+	return &response, nil
+}
+
+// UpdateProfile changes the fields present in req
+func (s *UserService) UpdateProfile(ctx context.Context, userID uuid.UUID, req models.UpdateProfileRequest) (*models.UserResponse, error) {
+	if req.SocialLinks != nil {
+		links := models.SocialLinks{}
+		for name, link := range *req.SocialLinks {
+			if link != "" {
+				links[name] = link
+			}
+		}
+		req.SocialLinks = &links
+	}
+
+	user, err := s.repo.UpdateProfile(ctx, userID, req)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+
+	response := userResponse(user)
+	return &response, nil
+}
+
+// ChangePassword sets a new password after checking the current one. All
+// sessions are signed out; personal access tokens keep working.
+func (s *UserService) ChangePassword(ctx context.Context, userID uuid.UUID, req models.ChangePasswordRequest, client models.ClientInfo) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return mapUserError(err)
+	}
+	if err := utils.VerifyPassword(user.PasswordHash, req.CurrentPassword); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	passwordHash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.ChangePassword(ctx, userID, passwordHash); err != nil {
+		return mapUserError(err)
+	}
+
+	s.audit(ctx, userID, models.ActionUpdate, client, userID, nil, map[string]interface{}{"password_changed": true})
+	return nil
+}
+
+// ChangeEmail moves the account to a new address after checking the
+// password. The new address is unverified until the user follows the link
+// sent to it.
+func (s *UserService) ChangeEmail(ctx context.Context, userID uuid.UUID, req models.ChangeEmailRequest, client models.ClientInfo) (*models.UserResponse, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+	if err := utils.VerifyPassword(user.PasswordHash, req.CurrentPassword); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	oldEmail := user.Email
+	if req.Email != oldEmail {
+		user, err = s.repo.ChangeEmail(ctx, userID, req.Email)
+		if err != nil {
+			return nil, mapUserError(err)
+		}
+
+		if err := s.authService.ResendVerification(ctx, user.Email); err != nil {
+			log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+		}
+		s.audit(ctx, userID, models.ActionUpdate, client, userID,
+			map[string]interface{}{"email": oldEmail}, map[string]interface{}{"email": user.Email})
+	}
+
+	response := userResponse(user)
+	return &response, nil
+}
+
+// RemoveAvatar deletes the user's uploaded avatar from storage and clears it
+// from the profile
+func (s *UserService) RemoveAvatar(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return mapUserError(err)
+	}
+	if user.AvatarURL == "" {
+		return nil
+	}
+
+	if err := s.avatars.DeleteAvatar(ctx, user.AvatarURL, userID.String()); err != nil {
+		return err
+	}
+	return mapUserError(s.repo.UpdateAvatarURL(ctx, userID, ""))
+}
+
+// List returns a page of users with their post counts
+func (s *UserService) List(ctx context.Context, filter models.UserFilter, page, pageSize int) (*models.PaginatedUserResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	offset := (page - 1) * pageSize
+
+	users, total, err := s.repo.List(ctx, filter, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	postCounts, err := s.repo.CountPosts(ctx, ids, "")
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]*models.AdminUserResponse, len(users))
+	for i, user := range users {
+		data[i] = adminUserResponse(user, postCounts[user.ID])
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	return &models.PaginatedUserResponse{
+		Data:       data,
+		Total:      int64(total),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (s *UserService) GetByID(ctx context.Context, id uuid.UUID) (*models.AdminUserResponse, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+	postCounts, err := s.repo.CountPosts(ctx, []uuid.UUID{user.ID}, "")
+	if err != nil {
+		return nil, err
+	}
+	return adminUserResponse(user, postCounts[user.ID]), nil
+}
+
+// UpdateRole takes effect on the user's next request
+func (s *UserService) UpdateRole(ctx context.Context, id uuid.UUID, role models.UserRole, adminID uuid.UUID, client models.ClientInfo) (*models.AdminUserResponse, error) {
+	if role != models.RoleAdmin && role != models.RoleUser {
+		return nil, ErrInvalidRole
+	}
+
+	oldRole, err := s.repo.UpdateRole(ctx, id, role)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+	if oldRole != role {
+		s.audit(ctx, adminID, models.ActionUpdate, client, id,
+			map[string]interface{}{"role": oldRole}, map[string]interface{}{"role": role})
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// SetActive activates or deactivates the user. Deactivating revokes their
+// sessions and personal access tokens, so they stay signed out if
+// reactivated.
+func (s *UserService) SetActive(ctx context.Context, id uuid.UUID, active bool, adminID uuid.UUID, client models.ClientInfo) (*models.AdminUserResponse, error) {
+	wasActive, err := s.repo.SetActive(ctx, id, active)
+	if err != nil {
+		return nil, mapUserError(err)
+	}
+	if wasActive != active {
+		s.audit(ctx, adminID, models.ActionUpdate, client, id,
+			map[string]interface{}{"is_active": wasActive}, map[string]interface{}{"is_active": active})
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Delete moves the user to the trash, from where it can be restored or purged
+func (s *UserService) Delete(ctx context.Context, id uuid.UUID, adminID uuid.UUID, client models.ClientInfo) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return mapUserError(err)
+	}
+	if err := s.personalTokenRepo.DeleteByUser(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit(ctx, adminID, models.ActionDelete, client, id, nil, nil)
+	return nil
+}
+
+// audit records an admin's change to a user. Failures are logged rather
+// than failing the request.
+func (s *UserService) audit(ctx context.Context, adminID uuid.UUID, action models.AuditAction, client models.ClientInfo, userID uuid.UUID, oldValues, newValues map[string]interface{}) {
+	if oldValues == nil {
+		oldValues = map[string]interface{}{}
+	}
+	if newValues == nil {
+		newValues = map[string]interface{}{}
+	}
+	oldValues["user_id"] = userID
+	newValues["user_id"] = userID
+
+	oldJSON, err := json.Marshal(oldValues)
+	if err != nil {
+		log.Printf("Failed to encode audit log values: %v", err)
+		return
+	}
+	newJSON, err := json.Marshal(newValues)
+	if err != nil {
+		log.Printf("Failed to encode audit log values: %v", err)
+		return
+	}
+
+	err = s.auditRepo.Create(ctx, &models.AuditLog{
+		UserID:    &adminID,
+		TableName: "users",
+		Action:    action,
+		OldValues: oldJSON,
+		NewValues: newJSON,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	})
+	if err != nil {
+		log.Printf("Failed to write audit log: %v", err)
+	}
+}
+
+func adminUserResponse(user *models.User, postCount int64) *models.AdminUserResponse {
+	return &models.AdminUserResponse{
+		UserResponse: userResponse(user),
+		PostCount:    postCount,
+		LockedUntil:  user.LockedUntil,
+		UpdatedAt:    user.UpdatedAt,
+	}
+}
+
+func mapUserError(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrUserNotFound):
+		return ErrUserNotFound
+	case errors.Is(err, repositories.ErrLastAdmin):
+		return ErrLastAdmin
+	case errors.Is(err, repositories.ErrEmailAlreadyExists):
+		return ErrEmailConflict
+	case errors.Is(err, repositories.ErrUsernameAlreadyExists):
+		return ErrUsernameConflict
+	default:
+		return err
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/repositories"
+)
+
+// botUserAgents are lower-case fragments of user agents that are not counted
+var botUserAgents = []string{
+	"bot", "crawl", "spider", "slurp", "preview", "headless",
+	"facebookexternalhit", "curl", "wget", "python-requests", "go-http-client",
+}
+
+// PageView describes the visitor behind a post view
+type PageView struct {
+	ClientIP  string
+	UserAgent string
+	Referrer  string
+	// Country is an ISO 3166-1 alpha-2 code, if known
+	Country string
+}
+
+// ViewCounter counts post views in memory and writes them to the database in
+// batches, both as post view counts and as daily analytics aggregates. A
+// visitor is counted once per post within the dedupe window.
+type ViewCounter interface {
+	Record(postID uuid.UUID, view PageView) bool
+	Flush(ctx context.Context) error
+	RunFlushJob(ctx context.Context, interval time.Duration)
+}
+
+type viewCounter struct {
+	repo     *repositories.AnalyticsRepository
+	window   time.Duration
+	location *time.Location
+
+	mu      sync.Mutex
+	pending map[models.ViewBucket]int
+	// seen maps a post and visitor fingerprint to the time it was counted
+	seen map[string]time.Time
+}
+
+// NewViewCounter creates a view counter; days are calendar days in location
+func NewViewCounter(repo *repositories.AnalyticsRepository, window time.Duration, location *time.Location) ViewCounter {
+	if location == nil {
+		location = time.UTC
+	}
+	return &viewCounter{
+		repo:     repo,
+		window:   window,
+		location: location,
+		pending:  make(map[models.ViewBucket]int),
+		seen:     make(map[string]time.Time),
+	}
+}
+
+// Record counts a view unless it comes from a bot or the visitor was already
+// counted for this post within the window. It reports whether it counted.
+func (v *viewCounter) Record(postID uuid.UUID, view PageView) bool {
+	if isBot(view.UserAgent) {
+		return false
+	}
+
+	key := viewFingerprint(postID, view.ClientIP, view.UserAgent)
+	now := time.Now()
+	bucket := models.ViewBucket{
+		PostID:       postID,
+		Day:          now.In(v.location).Format(time.DateOnly),
+		ReferrerHost: referrerHost(view.Referrer),
+		Country:      countryCode(view.Country),
+		Device:       deviceClass(view.UserAgent),
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if counted, ok := v.seen[key]; ok && now.Sub(counted) < v.window {
+		return false
+	}
+	v.seen[key] = now
+	v.pending[bucket]++
+	return true
+}
+
+// Flush writes the pending views. On failure they are kept for the next flush.
+func (v *viewCounter) Flush(ctx context.Context) error {
+	v.mu.Lock()
+	pending := v.pending
+	v.pending = make(map[models.ViewBucket]int)
+
+	cutoff := time.Now().Add(-v.window)
+	for key, counted := range v.seen {
+		if counted.Before(cutoff) {
+			delete(v.seen, key)
+		}
+	}
+	v.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := v.repo.SaveViews(pending); err != nil {
+		v.mu.Lock()
+		for bucket, count := range pending {
+			v.pending[bucket] += count
+		}
+		v.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// RunFlushJob calls Flush every interval until ctx is cancelled, then flushes
+// once more so no counted views are lost on shutdown
+func (v *viewCounter) RunFlushJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := v.Flush(context.Background()); err != nil {
+				log.Printf("Final view count flush failed: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := v.Flush(ctx); err != nil {
+				log.Printf("View count flush failed: %v", err)
+			}
+		}
+	}
+}
+
+func isBot(userAgent string) bool {
+	if userAgent == "" {
+		return true
+	}
+	userAgent = strings.ToLower(userAgent)
+	for _, fragment := range botUserAgents {
+		if strings.Contains(userAgent, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// viewFingerprint hashes the visitor so raw IP addresses are not kept in memory
+func viewFingerprint(postID uuid.UUID, clientIP, userAgent string) string {
+	sum := sha256.Sum256([]byte(postID.String() + "|" + clientIP + "|" + userAgent))
+	return hex.EncodeToString(sum[:])
+}
+
+// referrerHost reduces a Referer header to its host, without a www. prefix
+func referrerHost(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+	u, err := url.Parse(referrer)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if len(host) > 255 {
+		return ""
+	}
+	return host
+}
+
+func countryCode(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+		return ""
+	}
+	return country
+}
+
+func deviceClass(userAgent string) models.DeviceClass {
+	userAgent = strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(userAgent, "ipad") || strings.Contains(userAgent, "tablet") ||
+		(strings.Contains(userAgent, "android") && !strings.Contains(userAgent, "mobile")):
+		return models.DeviceTablet
+	case strings.Contains(userAgent, "mobi") || strings.Contains(userAgent, "iphone"):
+		return models.DeviceMobile
+	default:
+		return models.DeviceDesktop
+	}
+}
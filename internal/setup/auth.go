@@ -1,6 +1,7 @@
 package setup
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -15,38 +16,95 @@ import (
 	"github.com/kyomel/blog-management/internal/utils"
 )
 
+// dashboardCacheTTL is how long dashboard stats are reused before recomputing
+const dashboardCacheTTL = 30 * time.Second
+
 type AuthConfig struct {
-	AccessSecret  string
-	RefreshSecret string
+	JWT           configs.JWTConfig
 	AccessExpiry  time.Duration
 	RefreshExpiry time.Duration
 	Cloudinary    configs.CloudinaryConfig
+	// TrashRetention is how long soft-deleted content is kept; zero disables auto-purge
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+	// Timezone defines the calendar months of the archive
+	Timezone *time.Location
+	// ViewDedupeWindow is how long a visitor's repeat views of a post are ignored
+	ViewDedupeWindow  time.Duration
+	ViewFlushInterval time.Duration
+	// CountryHeader names the request header carrying the visitor's country
+	CountryHeader string
+	// BlogTitle and BlogURL describe the public site in feeds
+	BlogTitle         string
+	BlogURL           string
+	Mail              configs.MailConfig
+	PasswordReset     services.PasswordResetConfig
+	EmailVerification services.EmailVerificationConfig
+	// EmailTokenSecret signs email verification tokens
+	EmailTokenSecret string
+	EmailTokenExpiry time.Duration
+	TwoFactor        services.TwoFactorConfig
+	LoginThrottle    services.LoginThrottleConfig
+	Dashboard        services.DashboardConfig
+	OIDC             []configs.OIDCProviderConfig
 }
 
-func SetupAuth(router *gin.Engine, db *sql.DB, config AuthConfig) {
+// SetupAuth wires the services and registers all routes. The returned function
+// stops the background jobs that must finish before exit, such as flushing
+// buffered view counts.
+func SetupAuth(router *gin.Engine, db *sql.DB, config AuthConfig) func(ctx context.Context) {
 	userRepo := repositories.NewUserRepository(db)
+	userTokenRepo := repositories.NewUserTokenRepository(db)
+	twoFactorRepo := repositories.NewTwoFactorRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	oidcRepo := repositories.NewOIDCRepository(db)
+	personalTokenRepo := repositories.NewPersonalTokenRepository(db)
 	categoryRepo := repositories.NewCategoryRepository(db)
 	postRepo := repositories.NewPostRepository(db)
 	tagRepo := repositories.NewTagRepository(db)
+	slugHistoryRepo := repositories.NewSlugHistoryRepository(db)
+	trashRepo := repositories.NewTrashRepository(db)
+	backupRepo := repositories.NewBackupRepository(db)
+	analyticsRepo := repositories.NewAnalyticsRepository(db)
+	dashboardRepo := repositories.NewDashboardRepository(db)
 
-	jwtService := utils.NewJWTService(
-		config.AccessSecret,
-		config.RefreshSecret,
-		config.AccessExpiry,
-		config.RefreshExpiry,
-	)
+	jwtService, err := NewJWTService(config.JWT, config.AccessExpiry, config.RefreshExpiry)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize JWT keys: %v", err))
+	}
+
+	mailService, err := NewMailer(config.Mail)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize mailer: %v", err))
+	}
+
+	oidcProviders, err := NewOIDCProviders(config.OIDC)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize login providers: %v", err))
+	}
 
 	authService := services.NewAuthService(
 		userRepo,
+		userTokenRepo,
+		twoFactorRepo,
+		auditRepo,
+		oidcRepo,
+		personalTokenRepo,
 		jwtService,
+		utils.NewEmailTokenService(config.EmailTokenSecret, config.EmailTokenExpiry),
+		mailService,
 		config.AccessExpiry,
+		config.PasswordReset,
+		config.EmailVerification,
+		config.TwoFactor,
+		config.LoginThrottle,
+		oidcProviders,
 	)
 
-	categoryService := services.NewCategoryService(categoryRepo)
-	postService := services.NewPostService(postRepo)
-	tagService := services.NewTagService(tagRepo)
-
-	userService := services.NewUserService(userRepo)
+	categoryService := services.NewCategoryService(categoryRepo, slugHistoryRepo)
+	postService := services.NewPostService(postRepo, slugHistoryRepo)
+	tagService := services.NewTagService(tagRepo, slugHistoryRepo)
+	authorService := services.NewAuthorService(userRepo, postService)
 
 	cloudinaryService, err := cloudinary.NewCloudinaryService(
 		config.Cloudinary.CloudName,
@@ -58,13 +116,56 @@ func SetupAuth(router *gin.Engine, db *sql.DB, config AuthConfig) {
 		panic(fmt.Sprintf("Failed to initialize Cloudinary service: %v", err))
 	}
 
-	authMiddleware := middleware.NewAuthMiddleware(authService)
+	userService := services.NewUserService(userRepo, personalTokenRepo, auditRepo, authService, cloudinaryService)
+	personalTokenService := services.NewPersonalTokenService(personalTokenRepo)
+	trashService := services.NewTrashService(trashRepo)
+	backupService := services.NewBackupService(backupRepo)
+	archiveService := services.NewArchiveService(postRepo, postService, config.Timezone)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, postRepo, config.Timezone)
+	dashboardService := services.NewDashboardService(dashboardRepo, config.Dashboard, dashboardCacheTTL)
+
+	viewCounter := services.NewViewCounter(analyticsRepo, config.ViewDedupeWindow, config.Timezone)
+	viewCtx, stopViews := context.WithCancel(context.Background())
+	viewsFlushed := make(chan struct{})
+	go func() {
+		viewCounter.RunFlushJob(viewCtx, config.ViewFlushInterval)
+		close(viewsFlushed)
+	}()
+
+	if config.TrashRetention > 0 && config.TrashPurgeInterval > 0 {
+		go trashService.RunPurgeJob(context.Background(), config.TrashPurgeInterval, config.TrashRetention)
+	}
+
+	contentImporter, err := NewImporter(db, config.Cloudinary)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize importer: %v", err))
+	}
+
+	authMiddleware := middleware.NewAuthMiddleware(authService, config.EmailVerification.Policy == services.UnverifiedReadOnly)
 	authHandler := handlers.NewAuthHandler(authService)
+	personalTokenHandler := handlers.NewPersonalTokenHandler(personalTokenService)
+	profileHandler := handlers.NewProfileHandler(userService)
+	userHandler := handlers.NewUserHandler(userService)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
-	postHandler := handlers.NewPostHandler(postService)
+	postHandler := handlers.NewPostHandler(postService, viewCounter, config.CountryHeader)
 	tagHandler := handlers.NewTagHandler(tagService)
+	authorHandler := handlers.NewAuthorHandler(authorService, config.BlogTitle, config.BlogURL)
 
 	uploadHandler := handlers.NewUploadHandler(userService, cloudinaryService)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	importHandler := handlers.NewImportHandler(contentImporter, NewMarkdownService(db))
+	backupHandler := handlers.NewBackupHandler(backupService)
+	archiveHandler := handlers.NewArchiveHandler(archiveService)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
 
-	handlers.RegisterRoutes(router, authHandler, categoryHandler, postHandler, tagHandler, uploadHandler, authMiddleware)
+	handlers.RegisterRoutes(router, authHandler, personalTokenHandler, profileHandler, userHandler, categoryHandler, postHandler, tagHandler, authorHandler, uploadHandler, trashHandler, importHandler, backupHandler, archiveHandler, analyticsHandler, dashboardHandler, authMiddleware)
+
+	return func(ctx context.Context) {
+		stopViews()
+		select {
+		case <-viewsFlushed:
+		case <-ctx.Done():
+		}
+	}
 }
@@ -0,0 +1,49 @@
+package setup
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/kyomel/blog-management/configs"
+	"github.com/kyomel/blog-management/internal/repositories"
+	"github.com/kyomel/blog-management/internal/services"
+	"github.com/kyomel/blog-management/internal/services/cloudinary"
+	"github.com/kyomel/blog-management/internal/services/frontmatter"
+	"github.com/kyomel/blog-management/internal/services/importer"
+)
+
+// NewImporter builds the content importer used by the admin API and the import command
+func NewImporter(db *sql.DB, cloudinaryConfig configs.CloudinaryConfig) (*importer.Importer, error) {
+	slugHistoryRepo := repositories.NewSlugHistoryRepository(db)
+
+	mediaStore, err := cloudinary.NewCloudinaryService(
+		cloudinaryConfig.CloudName,
+		cloudinaryConfig.APIKey,
+		cloudinaryConfig.APISecret,
+		cloudinaryConfig.MediaFolder,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize media storage: %w", err)
+	}
+
+	return importer.NewImporter(
+		repositories.NewUserRepository(db),
+		repositories.NewMediaRepository(db),
+		services.NewCategoryService(repositories.NewCategoryRepository(db), slugHistoryRepo),
+		services.NewTagService(repositories.NewTagRepository(db), slugHistoryRepo),
+		services.NewPostService(repositories.NewPostRepository(db), slugHistoryRepo),
+		mediaStore,
+	), nil
+}
+
+// NewMarkdownService builds the Markdown front matter exporter and importer
+func NewMarkdownService(db *sql.DB) *frontmatter.Service {
+	slugHistoryRepo := repositories.NewSlugHistoryRepository(db)
+
+	return frontmatter.NewService(
+		repositories.NewUserRepository(db),
+		services.NewCategoryService(repositories.NewCategoryRepository(db), slugHistoryRepo),
+		services.NewTagService(repositories.NewTagRepository(db), slugHistoryRepo),
+		services.NewPostService(repositories.NewPostRepository(db), slugHistoryRepo),
+	)
+}
@@ -0,0 +1,68 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kyomel/blog-management/configs"
+	"github.com/kyomel/blog-management/internal/utils"
+)
+
+// NewJWTService loads the signing keys selected by JWT_ALGORITHM and the
+// retired keys that are still accepted
+func NewJWTService(config configs.JWTConfig, accessExpiry, refreshExpiry time.Duration) (utils.JWTService, error) {
+	var keys utils.JWTKeys
+	switch config.Algorithm {
+	case "HS256", "":
+		if config.AccessSecret == "" || config.RefreshSecret == "" {
+			return nil, fmt.Errorf("JWT_ACCESS_SECRET and JWT_REFRESH_SECRET are required for HS256")
+		}
+		keys.Access = utils.NewHMACKey(config.AccessSecret)
+		keys.Refresh = utils.NewHMACKey(config.RefreshSecret)
+
+	case "RS256", "EdDSA":
+		if config.SigningKeyFile == "" {
+			return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE is required for %s", config.Algorithm)
+		}
+		key, err := loadSigningKey(config.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if !key.CanSign() {
+			return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE %s holds no private key", config.SigningKeyFile)
+		}
+		if key.Method.Alg() != config.Algorithm {
+			return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE %s is a %s key, not %s", config.SigningKeyFile, key.Method.Alg(), config.Algorithm)
+		}
+		keys.Access, keys.Refresh = key, key
+
+	default:
+		return nil, fmt.Errorf("unknown JWT_ALGORITHM %q: must be HS256, RS256 or EdDSA", config.Algorithm)
+	}
+
+	for _, file := range configs.SplitList(config.VerificationKeyFiles) {
+		key, err := loadSigningKey(file)
+		if err != nil {
+			return nil, err
+		}
+		keys.Verification = append(keys.Verification, key)
+	}
+	for _, secret := range configs.SplitList(config.PreviousSecrets) {
+		keys.Verification = append(keys.Verification, utils.NewHMACKey(secret))
+	}
+
+	return utils.NewJWTService(keys, accessExpiry, refreshExpiry), nil
+}
+
+func loadSigningKey(path string) (*utils.SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key: %w", err)
+	}
+	key, err := utils.ParseSigningKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT key %s: %w", path, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,23 @@
+package setup
+
+import (
+	"fmt"
+
+	"github.com/kyomel/blog-management/configs"
+	"github.com/kyomel/blog-management/internal/services/mailer"
+)
+
+// NewMailer creates the mailer selected by MAIL_DRIVER
+func NewMailer(config configs.MailConfig) (mailer.Mailer, error) {
+	switch config.Driver {
+	case "smtp":
+		if config.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for the smtp mail driver")
+		}
+		return mailer.NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.From), nil
+	case "log", "":
+		return mailer.NewLogMailer(config.LogDir, config.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", config.Driver)
+	}
+}
@@ -0,0 +1,42 @@
+package setup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kyomel/blog-management/configs"
+	"github.com/kyomel/blog-management/internal/models"
+	"github.com/kyomel/blog-management/internal/services"
+	"github.com/kyomel/blog-management/internal/services/oidc"
+)
+
+// NewOIDCProviders creates the login providers listed in OIDC_PROVIDERS.
+// Nothing is fetched from the providers until they are first used.
+func NewOIDCProviders(providers []configs.OIDCProviderConfig) ([]services.OIDCProvider, error) {
+	result := make([]services.OIDCProvider, 0, len(providers))
+	for _, config := range providers {
+		if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+			return nil, fmt.Errorf("OIDC provider %q needs an issuer, client ID and redirect URL", config.Name)
+		}
+
+		role := models.UserRole(config.DefaultRole)
+		switch role {
+		case models.RoleUser, models.RoleAdmin:
+		default:
+			return nil, fmt.Errorf("OIDC provider %q has invalid default role %q", config.Name, config.DefaultRole)
+		}
+
+		result = append(result, services.OIDCProvider{
+			Provider: oidc.NewProvider(oidc.Config{
+				Name:         config.Name,
+				Issuer:       config.Issuer,
+				ClientID:     config.ClientID,
+				ClientSecret: config.ClientSecret,
+				RedirectURL:  config.RedirectURL,
+				Scopes:       strings.Fields(config.Scopes),
+			}),
+			DefaultRole: role,
+		})
+	}
+	return result, nil
+}
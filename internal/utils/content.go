@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"bytes"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+var (
+	markdownRenderer = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	)
+	htmlPolicy = newHTMLPolicy()
+)
+
+func newHTMLPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	// Keep heading anchors and code highlighting hints
+	policy.AllowAttrs("id").OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	policy.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).OnElements("code", "pre")
+	return policy
+}
+
+// RenderMarkdown converts markdown source to sanitized HTML
+func RenderMarkdown(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return SanitizeHTML(buf.String()), nil
+}
+
+// SanitizeHTML strips any elements and attributes that are not on the allowlist
+func SanitizeHTML(html string) string {
+	return htmlPolicy.Sanitize(html)
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// emailTokenAudience keeps email tokens from being accepted anywhere else
+const emailTokenAudience = "email-verification"
+
+type emailTokenClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// EmailTokenService issues signed, expiring tokens proving control of an
+// email address. They are stateless; the email is part of the signed claims
+// so a token stops working once the user changes address.
+type EmailTokenService interface {
+	Generate(userID uuid.UUID, email string) (string, error)
+	Validate(token string) (uuid.UUID, string, error)
+}
+
+type emailTokenService struct {
+	secret []byte
+	expiry time.Duration
+}
+
+func NewEmailTokenService(secret string, expiry time.Duration) EmailTokenService {
+	return &emailTokenService{
+		secret: []byte(secret),
+		expiry: expiry,
+	}
+}
+
+func (s *emailTokenService) Generate(userID uuid.UUID, email string) (string, error) {
+	now := time.Now()
+	claims := emailTokenClaims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{emailTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.expiry)),
+			Issuer:    "blog-management-api",
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+// Validate returns the user ID and email the token was issued for
+func (s *emailTokenService) Validate(token string) (uuid.UUID, string, error) {
+	claims := &emailTokenClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(emailTokenAudience),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return uuid.Nil, "", ErrExpiredToken
+		}
+		return uuid.Nil, "", ErrInvalidToken
+	}
+	if !parsed.Valid {
+		return uuid.Nil, "", ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidToken
+	}
+	return userID, claims.Email, nil
+}
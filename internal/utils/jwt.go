@@ -13,11 +13,33 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// Token types, carried in the typ claim. A token is only accepted where its
+// type is expected, so a refresh token cannot be used as an access token.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+const jwtIssuer = "blog-management-api"
+
 type JWTClaims struct {
 	UserID   uuid.UUID `json:"user_id"`
 	Username string    `json:"username"`
 	Email    string    `json:"email"`
 	Role     string    `json:"role"`
+	Type     string    `json:"typ"`
+	// TokenVersion must match the user's current version for the token to be accepted
+	TokenVersion int `json:"tv"`
+	// EmailVerified is not part of the token; it is filled in from the user
+	// record when a request is authenticated
+	EmailVerified bool `json:"-"`
+	// TwoFactorSetupRequired is filled in the same way; such tokens may only
+	// be used to enrol in two-factor authentication
+	TwoFactorSetupRequired bool `json:"-"`
+	// Scopes is set when the request used a personal access token and limits
+	// it to those scopes. It is nil for session tokens, which may do anything
+	// the user's role allows.
+	Scopes []string `json:"-"`
 	jwt.RegisteredClaims
 }
 
@@ -26,125 +48,135 @@ type TokenPair struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// JWTKeys are the keys of a JWTService. Access and Refresh sign new tokens
+// of their type; Verification keys are also accepted, so tokens signed with
+// a retired key stay valid until they expire.
+type JWTKeys struct {
+	Access       *SigningKey
+	Refresh      *SigningKey
+	Verification []*SigningKey
+}
+
 type JWTService interface {
-	GenerateTokenPair(userID uuid.UUID, username, email, role string) (*TokenPair, error)
+	GenerateTokenPair(userID uuid.UUID, username, email, role string, tokenVersion int) (*TokenPair, error)
 	ValidateToken(tokenString string) (*JWTClaims, error)
-	RefreshTokens(refreshToken string) (*TokenPair, error)
+	ValidateRefreshToken(tokenString string) (*JWTClaims, error)
+	// JWKS returns the public keys other services can verify tokens with
+	JWKS() JWKSet
 }
 
 type jwtService struct {
-	accessSecret  []byte
-	refreshSecret []byte
+	accessKey     *SigningKey
+	refreshKey    *SigningKey
+	accessKeys    map[string]*SigningKey
+	refreshKeys   map[string]*SigningKey
+	jwks          JWKSet
 	accessExpiry  time.Duration
 	refreshExpiry time.Duration
 }
 
-func NewJWTService(accessSecret, refreshSecret string, accessExpiry, refreshExpiry time.Duration) JWTService {
-	return &jwtService{
-		accessSecret:  []byte(accessSecret),
-		refreshSecret: []byte(refreshSecret),
+func NewJWTService(keys JWTKeys, accessExpiry, refreshExpiry time.Duration) JWTService {
+	s := &jwtService{
+		accessKey:     keys.Access,
+		refreshKey:    keys.Refresh,
+		accessKeys:    map[string]*SigningKey{keys.Access.ID: keys.Access},
+		refreshKeys:   map[string]*SigningKey{keys.Refresh.ID: keys.Refresh},
+		jwks:          JWKSet{Keys: []JWK{}},
 		accessExpiry:  accessExpiry,
 		refreshExpiry: refreshExpiry,
 	}
-}
 
-func (s *jwtService) GenerateTokenPair(userID uuid.UUID, username, email, role string) (*TokenPair, error) {
-	accessClaims := JWTClaims{
-		UserID:   userID,
-		Username: username,
-		Email:    email,
-		Role:     role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.accessExpiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "blog-management-api",
-			Subject:   userID.String(),
-		},
+	for _, key := range keys.Verification {
+		s.accessKeys[key.ID] = key
+		s.refreshKeys[key.ID] = key
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString(s.accessSecret)
-	if err != nil {
-		return nil, err
+	published := make(map[string]bool)
+	for _, key := range append([]*SigningKey{keys.Access, keys.Refresh}, keys.Verification...) {
+		if jwk, ok := key.JWK(); ok && !published[key.ID] {
+			s.jwks.Keys = append(s.jwks.Keys, jwk)
+			published[key.ID] = true
+		}
 	}
+	return s
+}
 
-	refreshClaims := JWTClaims{
-		UserID:   userID,
-		Username: username,
-		Email:    email,
-		Role:     role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.refreshExpiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "blog-management-api",
-			Subject:   userID.String(),
-		},
+func (s *jwtService) GenerateTokenPair(userID uuid.UUID, username, email, role string, tokenVersion int) (*TokenPair, error) {
+	accessToken, err := s.sign(s.accessKey, TokenTypeAccess, s.accessExpiry, userID, username, email, role, tokenVersion)
+	if err != nil {
+		return nil, err
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString(s.refreshSecret)
+	refreshToken, err := s.sign(s.refreshKey, TokenTypeRefresh, s.refreshExpiry, userID, username, email, role, tokenVersion)
 	if err != nil {
 		return nil, err
 	}
 
 	return &TokenPair{
-		AccessToken:  accessTokenString,
-		RefreshToken: refreshTokenString,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 	}, nil
 }
 
-func (s *jwtService) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return s.accessSecret, nil
-	})
-
-	if err == nil && token.Valid {
-		if claims, ok := token.Claims.(*JWTClaims); ok {
-			return claims, nil
-		}
-	}
-
-	if err != nil && !errors.Is(err, jwt.ErrTokenExpired) {
-		token, err = jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			return s.refreshSecret, nil
-		})
-
-		if err == nil && token.Valid {
-			if claims, ok := token.Claims.(*JWTClaims); ok {
-				return claims, nil
-			}
-		}
+func (s *jwtService) sign(key *SigningKey, tokenType string, expiry time.Duration, userID uuid.UUID, username, email, role string, tokenVersion int) (string, error) {
+	now := time.Now()
+	claims := JWTClaims{
+		UserID:       userID,
+		Username:     username,
+		Email:        email,
+		Role:         role,
+		Type:         tokenType,
+		TokenVersion: tokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    jwtIssuer,
+			Subject:   userID.String(),
+		},
 	}
 
-	if errors.Is(err, jwt.ErrTokenExpired) {
-		return nil, ErrExpiredToken
-	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
+}
 
-	return nil, ErrInvalidToken
+// ValidateToken accepts access tokens only
+func (s *jwtService) ValidateToken(tokenString string) (*JWTClaims, error) {
+	return s.parse(tokenString, TokenTypeAccess, s.accessKeys)
 }
 
-func (s *jwtService) RefreshTokens(refreshToken string) (*TokenPair, error) {
-	token, err := jwt.ParseWithClaims(refreshToken, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return s.refreshSecret, nil
-	})
+// ValidateRefreshToken accepts refresh tokens only
+func (s *jwtService) ValidateRefreshToken(tokenString string) (*JWTClaims, error) {
+	return s.parse(tokenString, TokenTypeRefresh, s.refreshKeys)
+}
 
-	if err != nil {
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			return nil, ErrExpiredToken
-		}
-		return nil, err
-	}
+func (s *jwtService) JWKS() JWKSet {
+	return s.jwks
+}
 
-	if !token.Valid {
-		return nil, ErrInvalidToken
+// parse verifies the token with the key named by its kid header. The key
+// fixes the algorithm, so a token cannot pick a weaker one.
+func (s *jwtService) parse(tokenString, tokenType string, keys map[string]*SigningKey) (*JWTClaims, error) {
+	claims := &JWTClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			key, ok := keys[kid]
+			if !ok || token.Method.Alg() != key.Method.Alg() {
+				return nil, ErrInvalidToken
+			}
+			return key.public, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(jwtIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, ErrExpiredToken
 	}
-
-	claims, ok := token.Claims.(*JWTClaims)
-	if !ok {
+	if err != nil || claims.Type != tokenType {
 		return nil, ErrInvalidToken
 	}
-
-	return s.GenerateTokenPair(claims.UserID, claims.Username, claims.Email, claims.Role)
+	return claims, nil
 }
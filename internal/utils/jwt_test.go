@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	testAccessSecret  = "access-secret-that-is-long-enough-for-tests"
+	testRefreshSecret = "refresh-secret-that-is-long-enough-for-tests"
+)
+
+func newTestJWTService(t *testing.T, verification ...*SigningKey) JWTService {
+	t.Helper()
+	return NewJWTService(JWTKeys{
+		Access:       NewHMACKey(testAccessSecret),
+		Refresh:      NewHMACKey(testRefreshSecret),
+		Verification: verification,
+	}, time.Minute, time.Hour)
+}
+
+// signTestToken signs claims for an access token with an arbitrary method,
+// key and kid, the way an attacker could
+func signTestToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, mutate func(*JWTClaims)) string {
+	t.Helper()
+	now := time.Now()
+	claims := JWTClaims{
+		UserID: uuid.New(),
+		Role:   "admin",
+		Type:   TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    jwtIssuer,
+		},
+	}
+	if mutate != nil {
+		mutate(&claims)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestValidateTokenAcceptsAccessTokens(t *testing.T) {
+	service := newTestJWTService(t)
+	userID := uuid.New()
+
+	pair, err := service.GenerateTokenPair(userID, "jane", "jane@example.com", "user", 3)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair: %v", err)
+	}
+
+	claims, err := service.ValidateToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if claims.UserID != userID || claims.TokenVersion != 3 || claims.Type != TokenTypeAccess {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	if _, err := service.ValidateRefreshToken(pair.RefreshToken); err != nil {
+		t.Errorf("ValidateRefreshToken: %v", err)
+	}
+}
+
+func TestValidateTokenRejectsWrongTokenType(t *testing.T) {
+	service := newTestJWTService(t)
+
+	pair, err := service.GenerateTokenPair(uuid.New(), "jane", "jane@example.com", "user", 0)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair: %v", err)
+	}
+
+	if _, err := service.ValidateToken(pair.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateToken(refresh token) = %v, want ErrInvalidToken", err)
+	}
+	if _, err := service.ValidateRefreshToken(pair.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateRefreshToken(access token) = %v, want ErrInvalidToken", err)
+	}
+
+	// A refresh token relabelled as access but signed with the refresh key
+	// must still fail, because the refresh key does not verify access tokens
+	refreshKey := NewHMACKey(testRefreshSecret)
+	relabelled := signTestToken(t, jwt.SigningMethodHS256, refreshKey.private, refreshKey.ID, nil)
+	if _, err := service.ValidateToken(relabelled); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateToken(token signed with refresh key) = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestValidateTokenRejectsBadKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	rsaVerification, err := ParseSigningKey(publicPEM)
+	if err != nil {
+		t.Fatalf("ParseSigningKey: %v", err)
+	}
+
+	service := newTestJWTService(t, rsaVerification)
+	access := NewHMACKey(testAccessSecret)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{
+			name:  "unknown kid",
+			token: signTestToken(t, jwt.SigningMethodHS256, []byte(testAccessSecret), "hs-000000000000", nil),
+		},
+		{
+			name:  "missing kid",
+			token: signTestToken(t, jwt.SigningMethodHS256, []byte(testAccessSecret), "", nil),
+		},
+		{
+			name:  "unconfigured secret",
+			token: signTestToken(t, jwt.SigningMethodHS256, []byte("some-other-secret-of-sufficient-length"), NewHMACKey("some-other-secret-of-sufficient-length").ID, nil),
+		},
+		{
+			name:  "alg differs from the key",
+			token: signTestToken(t, jwt.SigningMethodHS512, []byte(testAccessSecret), access.ID, nil),
+		},
+		{
+			// The classic confusion attack: HMAC keyed with the public RSA key
+			name:  "HS256 under an RSA kid",
+			token: signTestToken(t, jwt.SigningMethodHS256, publicPEM, rsaVerification.ID, nil),
+		},
+		{
+			name:  "EdDSA under an HMAC kid",
+			token: signTestToken(t, jwt.SigningMethodEdDSA, edKey, access.ID, nil),
+		},
+		{
+			name: "wrong issuer",
+			token: signTestToken(t, jwt.SigningMethodHS256, []byte(testAccessSecret), access.ID, func(c *JWTClaims) {
+				c.Issuer = "someone-else"
+			}),
+		},
+		{
+			name: "no expiry",
+			token: signTestToken(t, jwt.SigningMethodHS256, []byte(testAccessSecret), access.ID, func(c *JWTClaims) {
+				c.ExpiresAt = nil
+			}),
+		},
+		{
+			name: "no type",
+			token: signTestToken(t, jwt.SigningMethodHS256, []byte(testAccessSecret), access.ID, func(c *JWTClaims) {
+				c.Type = ""
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.ValidateToken(tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("ValidateToken = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, JWTClaims{Type: TokenTypeAccess})
+	unsigned.Header["kid"] = access.ID
+	none, err := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	if _, err := service.ValidateToken(none); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateToken(alg none) = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestValidateTokenExpired(t *testing.T) {
+	service := newTestJWTService(t)
+	access := NewHMACKey(testAccessSecret)
+
+	expired := signTestToken(t, jwt.SigningMethodHS256, []byte(testAccessSecret), access.ID, func(c *JWTClaims) {
+		c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	})
+	if _, err := service.ValidateToken(expired); !errors.Is(err, ErrExpiredToken) {
+		t.Errorf("ValidateToken = %v, want ErrExpiredToken", err)
+	}
+}
+
+func TestValidateTokenAcceptsRetiredKeys(t *testing.T) {
+	const retiredSecret = "retired-secret-that-is-long-enough-for-tests"
+	retired := NewHMACKey(retiredSecret)
+	service := newTestJWTService(t, retired)
+
+	token := signTestToken(t, jwt.SigningMethodHS256, []byte(retiredSecret), retired.ID, nil)
+	if _, err := service.ValidateToken(token); err != nil {
+		t.Errorf("ValidateToken(token from retired key) = %v", err)
+	}
+}
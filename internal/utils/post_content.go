@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/kyomel/blog-management/internal/models"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const wordsPerMinute = 200
+
+var ErrInvalidContentFormat = errors.New("invalid content format")
+
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1,
+	atom.H2: 2,
+	atom.H3: 3,
+	atom.H4: 4,
+	atom.H5: 5,
+	atom.H6: 6,
+}
+
+// RenderContent produces the sanitized HTML for post content in the given format
+func RenderContent(format models.ContentFormat, content string) (string, error) {
+	switch format {
+	case models.FormatMarkdown:
+		return RenderMarkdown(content)
+	case models.FormatHTML, "":
+		return SanitizeHTML(content), nil
+	default:
+		return "", ErrInvalidContentFormat
+	}
+}
+
+// ApplyPostContent renders the post's content and stores the derived HTML,
+// word count, reading time and heading outline on the post
+func ApplyPostContent(post *models.Post) error {
+	contentHTML, err := RenderContent(post.ContentFormat, post.Content)
+	if err != nil {
+		return err
+	}
+
+	root := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(contentHTML), root)
+	if err != nil {
+		return err
+	}
+
+	var words int
+	outline := []models.PostHeading{}
+	usedIDs := map[string]bool{}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			words += len(strings.Fields(n.Data))
+			return
+		}
+
+		if level, ok := headingLevels[n.DataAtom]; ok && n.Type == html.ElementNode {
+			text := strings.Join(strings.Fields(nodeText(n)), " ")
+			id := headingID(n)
+			if id == "" {
+				id = uniqueAnchor(anchorFromText(text), usedIDs)
+				n.Attr = append(n.Attr, html.Attribute{Key: "id", Val: id})
+			}
+			usedIDs[id] = true
+			outline = append(outline, models.PostHeading{Level: level, Text: text, ID: id})
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		walk(n)
+		if err := html.Render(&buf, n); err != nil {
+			return err
+		}
+	}
+
+	outlineJSON, err := json.Marshal(outline)
+	if err != nil {
+		return err
+	}
+
+	post.ContentHTML = buf.String()
+	post.WordCount = words
+	post.ReadingTime = readingTime(words)
+	post.Outline = outlineJSON
+	return nil
+}
+
+// readingTime estimates the minutes needed to read the given number of words
+func readingTime(words int) int {
+	if words == 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(words) / wordsPerMinute))
+}
+
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(nodeText(c))
+	}
+	return sb.String()
+}
+
+func headingID(n *html.Node) string {
+	for _, attr := range n.Attr {
+		if attr.Key == "id" {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// anchorFromText builds a lowercase, hyphen separated anchor id from heading text
+func anchorFromText(text string) string {
+	var sb strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(r)
+			hyphen = false
+		case sb.Len() > 0 && !hyphen:
+			sb.WriteRune('-')
+			hyphen = true
+		}
+	}
+
+	anchor := strings.TrimSuffix(sb.String(), "-")
+	if anchor == "" {
+		return "heading"
+	}
+	return anchor
+}
+
+func uniqueAnchor(anchor string, used map[string]bool) string {
+	if !used[anchor] {
+		return anchor
+	}
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", anchor, i)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxSlugLength = 200
+
+// transliterations covers letters that do not decompose into a latin base letter
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'ø': "o", 'œ': "oe", 'đ': "d", 'ð': "d", 'ł': "l", 'þ': "th", 'ı': "i",
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i",
+	'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+	'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Slugify converts text into a lowercase, hyphen separated ASCII slug
+func Slugify(text string) string {
+	var sb strings.Builder
+	hyphen := false
+
+	writeHyphen := func() {
+		if sb.Len() > 0 && !hyphen {
+			sb.WriteByte('-')
+			hyphen = true
+		}
+	}
+
+	for _, r := range norm.NFKD.String(strings.ToLower(text)) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			sb.WriteRune(r)
+			hyphen = false
+		case unicode.Is(unicode.Mn, r):
+			// Drop combining marks left over from decomposing accented letters
+		case transliterations[r] != "":
+			sb.WriteString(transliterations[r])
+			hyphen = false
+		case unicode.IsLetter(r):
+			// Letters without a known transliteration are dropped
+		case r == '\'' || r == '’':
+			// Apostrophes join words rather than splitting them
+		default:
+			writeHyphen()
+		}
+	}
+
+	slug := strings.Trim(sb.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
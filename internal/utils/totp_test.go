@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed of RFC 6238 appendix B, "12345678901234567890"
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC lists 8 digit codes; 6 digit codes are their last six digits
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+
+	for _, v := range rfc6238Vectors {
+		if got := totpCode(key, v.unix/totpPeriod); got != v.code {
+			t.Errorf("totpCode at %d = %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestValidateTOTPRFC6238(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		step, ok := ValidateTOTP(rfc6238Secret, v.code, time.Unix(v.unix, 0))
+		if !ok {
+			t.Errorf("ValidateTOTP(%s) at %d rejected", v.code, v.unix)
+			continue
+		}
+		if step != v.unix/totpPeriod {
+			t.Errorf("ValidateTOTP(%s) at %d matched step %d, want %d", v.code, v.unix, step, v.unix/totpPeriod)
+		}
+	}
+
+	// Secrets are accepted in lower case and codes with spaces, as typed
+	if _, ok := ValidateTOTP(strings.ToLower(rfc6238Secret), "287 082", time.Unix(59, 0)); !ok {
+		t.Error("ValidateTOTP rejected a lower case secret or a spaced code")
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	const unix = 1111111109
+	code := "081804"
+
+	tests := []struct {
+		name   string
+		offset int64
+		want   bool
+	}{
+		{"same period", 0, true},
+		{"one period later", totpPeriod, true},
+		{"one period earlier", -totpPeriod, true},
+		{"two periods later", 2 * totpPeriod, false},
+		{"two periods earlier", -2 * totpPeriod, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(rfc6238Secret, code, time.Unix(unix+tt.offset, 0)); ok != tt.want {
+				t.Errorf("ValidateTOTP = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPRejectsMalformed(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "abcdef", "287083"} {
+		if _, ok := ValidateTOTP(rfc6238Secret, code, now); ok {
+			t.Errorf("ValidateTOTP(%q) accepted", code)
+		}
+	}
+	if _, ok := ValidateTOTP("not base32!", "287082", now); ok {
+		t.Error("ValidateTOTP accepted an invalid secret")
+	}
+}